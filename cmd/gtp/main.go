@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/gtp"
+)
+
+func main() {
+	difficulty := flag.String("difficulty", "medium", "AI difficulty: random, easy, medium, hard or mcts")
+	playouts := flag.Int("playouts", 0, "simulations per move for the mcts difficulty (0 for the default)")
+	rules := flag.String("rules", "", "ruleset: japanese, chinese, aga, new_zealand, tromp-taylor or ing (default Chinese scoring)")
+	resignMargin := flag.Float64("resign-margin", 0, "resign when the score estimate is this many points behind (0 never resigns)")
+	seed := flag.Int64("seed", 0, "seed for reproducible moves (0 for a random seed)")
+	verbose := flag.Bool("verbose", false, "log AI diagnostics to stderr")
+	flag.Parse()
+
+	// GTP owns stdout; AI logging goes to stderr and is off by default so
+	// that controllers which capture stderr are not flooded.
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	engine := gtp.NewEngine(*difficulty)
+	engine.Playouts = *playouts
+	engine.Seed = *seed
+	engine.ResignMargin = *resignMargin
+	if *rules != "" {
+		if _, err := engine.Execute("kgs-rules", []string{*rules}); err != nil {
+			log.SetOutput(os.Stderr)
+			log.Fatalf("%s: %v", *rules, err)
+		}
+	}
+	if err := engine.Run(os.Stdin, os.Stdout); err != nil {
+		log.SetOutput(os.Stderr)
+		log.Fatal(err)
+	}
+}
@@ -1,21 +1,58 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/Prawal-Sharma/GoSim/pkg/game"
+	"github.com/Prawal-Sharma/GoSim/pkg/gtp"
 	"github.com/Prawal-Sharma/GoSim/pkg/websocket"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 )
 
+// Limits on the search budget a client can request from /api/ai-move.
+const (
+	maxPlayouts = 100000
+	maxMoveTime = 30 * time.Second
+)
+
+// engineFlags collects -gtp-engine flags of the form name=command args.
+type engineFlags []string
+
+func (f *engineFlags) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *engineFlags) Set(value string) error {
+	name, command, ok := strings.Cut(value, "=")
+	if !ok || name == "" || strings.TrimSpace(command) == "" {
+		return fmt.Errorf("expected name=command, got %q", value)
+	}
+	fields := strings.Fields(command)
+	gtp.RegisterEngine(name, fields[0], fields[1:]...)
+	*f = append(*f, name)
+	return nil
+}
+
 func main() {
+	var gtpEngines engineFlags
+	flag.Var(&gtpEngines, "gtp-engine", "register an external GTP engine as name=command (repeatable)")
+	seed := flag.Int64("seed", 0, "seed for room IDs, to reproduce a session (0 for a random seed)")
+	grace := flag.Duration("grace", websocket.DefaultGracePeriod, "time a disconnected player has to reconnect before forfeiting")
+	flag.Parse()
+
 	r := chi.NewRouter()
 
 	r.Use(middleware.Logger)
@@ -30,6 +67,10 @@ func main() {
 	}))
 
 	hub := websocket.NewHub()
+	if *seed != 0 {
+		hub.SetSeed(*seed)
+	}
+	hub.GracePeriod = *grace
 	go hub.Run()
 
 	// Serve static files
@@ -53,10 +94,12 @@ func main() {
 
 	r.Post("/api/ai-move", func(w http.ResponseWriter, r *http.Request) {
 		var req struct {
-			Board      [][]int `json:"board"`
-			BoardSize  int     `json:"boardSize"`
+			boardRequest
 			Color      string  `json:"color"`
 			Difficulty string  `json:"difficulty"`
+			Playouts   int     `json:"playouts"`
+			MoveTime   int     `json:"moveTime"`
+			Seed       *int64  `json:"seed"`
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -64,14 +107,10 @@ func main() {
 			return
 		}
 
-		boardGame := game.NewGame(req.BoardSize)
-		for x := 0; x < req.BoardSize; x++ {
-			for y := 0; y < req.BoardSize; y++ {
-				color := game.Color(req.Board[x][y])
-				if color != game.Empty {
-					boardGame.Board.SetStone(game.Point{X: x, Y: y}, color)
-				}
-			}
+		boardGame, err := req.game()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 
 		var playerColor game.Color
@@ -84,19 +123,110 @@ func main() {
 		// Set the current turn to AI's color so it can make a move
 		boardGame.CurrentTurn = playerColor
 
-		move := getAIMove(boardGame, playerColor, req.Difficulty)
+		if req.Difficulty == "" {
+			req.Difficulty = "easy"
+		}
+		engine, err := game.NewEngine(req.Difficulty)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("%v: %q", err, req.Difficulty), http.StatusBadRequest)
+			return
+		}
+		if closer, ok := engine.(io.Closer); ok {
+			defer closer.Close()
+		}
+
+		// Without a moveTime the search gets maxMoveTime as a safety net,
+		// which MCTS would otherwise use in full, so it gets a playout
+		// budget instead.
+		budget := time.Duration(req.MoveTime) * time.Millisecond
+		if budget <= 0 || budget > maxMoveTime {
+			budget = maxMoveTime
+		}
+		if ai, ok := engine.(*game.AI); ok {
+			if req.Playouts > maxPlayouts {
+				req.Playouts = maxPlayouts
+			}
+			if req.Playouts == 0 && req.MoveTime <= 0 {
+				req.Playouts = game.DefaultPlayouts
+			}
+			ai.Playouts = req.Playouts
+		}
+
+		response := map[string]interface{}{}
+		if seeder, ok := engine.(game.Seeder); ok {
+			if req.Seed != nil {
+				seeder.SetSeed(*req.Seed)
+			}
+			response["seed"] = seeder.Seed()
+		}
+
+		// The request's context is cancelled if the client goes away.
+		ctx, cancel := context.WithTimeout(r.Context(), budget)
+		defer cancel()
+		var move *game.Point
+		resign := false
+		if fallible, ok := engine.(game.FallibleEngine); ok {
+			move, err = fallible.TryGenerateMove(ctx, boardGame, playerColor)
+			resign = errors.Is(err, game.ErrEngineResigned)
+		} else {
+			move = engine.GenerateMove(ctx, boardGame, playerColor)
+		}
+		if r.Context().Err() != nil {
+			log.Printf("AI move abandoned: %v", r.Context().Err())
+			return
+		}
+		if err != nil && !resign {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
 
 		w.Header().Set("Content-Type", "application/json")
-		if move != nil {
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"x": move.X,
-				"y": move.Y,
-			})
+		if resign {
+			response["resign"] = true
+		} else if move != nil {
+			response["x"] = move.X
+			response["y"] = move.Y
+			response["vertex"] = boardGame.Board.Label(*move)
 		} else {
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"pass": true,
-			})
+			response["pass"] = true
 		}
+		json.NewEncoder(w).Encode(response)
+	})
+
+	r.Post("/api/pass-alive", func(w http.ResponseWriter, r *http.Request) {
+		var req boardRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		boardGame, err := req.game()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		response := map[string]interface{}{}
+		for _, color := range []game.Color{game.Black, game.White} {
+			alive := game.Benson(boardGame.Board, color)
+			chains := [][]map[string]int{}
+			for _, chain := range alive.Chains {
+				chains = append(chains, pointsJSON(chain))
+			}
+			response[strings.ToLower(color.String())] = map[string]interface{}{
+				"chains":    chains,
+				"territory": pointsJSON(alive.Territory),
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	})
+
+	r.Get("/api/engines", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(game.Engines())
 	})
 
 	r.Get("/api/puzzles", func(w http.ResponseWriter, r *http.Request) {
@@ -115,11 +245,54 @@ func main() {
 	log.Fatal(http.ListenAndServe(":8081", r))
 }
 
-func getAIMove(g *game.Game, color game.Color, difficulty string) *game.Point {
-	ai := game.NewAI(color, difficulty)
-	return ai.GetMove(g)
+// boardRequest is a position sent to the API, indexed board[x][y]. A
+// rectangular board gives boardWidth and boardHeight instead of boardSize.
+type boardRequest struct {
+	Board       [][]int `json:"board"`
+	BoardSize   int     `json:"boardSize"`
+	BoardWidth  int     `json:"boardWidth"`
+	BoardHeight int     `json:"boardHeight"`
+}
+
+// game sets up a game holding the stones of the request's board.
+func (req boardRequest) game() (*game.Game, error) {
+	width, height := req.BoardWidth, req.BoardHeight
+	if width == 0 && height == 0 {
+		width, height = req.BoardSize, req.BoardSize
+	}
+	g, err := game.NewRectangularGame(width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	board := req.Board
+	if len(board) != width {
+		return nil, fmt.Errorf("board has %d columns, expected %d", len(board), width)
+	}
+	for x := 0; x < width; x++ {
+		if len(board[x]) != height {
+			return nil, fmt.Errorf("board column %d has %d points, expected %d", x, len(board[x]), height)
+		}
+		for y := 0; y < height; y++ {
+			color := game.Color(board[x][y])
+			if color != game.Empty && color != game.Black && color != game.White {
+				return nil, fmt.Errorf("board point %d,%d is %d, expected 0, 1 or 2", x, y, board[x][y])
+			}
+			if color != game.Empty {
+				g.Board.SetStone(game.Point{X: x, Y: y}, color)
+			}
+		}
+	}
+	return g, nil
 }
 
+func pointsJSON(points []game.Point) []map[string]int {
+	result := make([]map[string]int, 0, len(points))
+	for _, p := range points {
+		result = append(result, map[string]int{"x": p.X, "y": p.Y})
+	}
+	return result
+}
 
 func loadPuzzles() []map[string]interface{} {
 	puzzles := []map[string]interface{}{}
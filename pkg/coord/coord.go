@@ -0,0 +1,159 @@
+// Package coord converts board points to and from the notations used for
+// Go: GTP vertices such as "D4", SGF points such as "dd", and the column
+// and row labels drawn around a board.
+package coord
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalid  = errors.New("invalid coordinate")
+	ErrOffBoard = errors.New("coordinate is off the board")
+)
+
+// Columns are the column letters in Go convention, which skips I.
+const Columns = "ABCDEFGHJKLMNOPQRSTUVWXYZ"
+
+// Point is a point on the board. X counts columns from the left edge and
+// Y counts rows from the top edge, both from 0.
+type Point struct {
+	X, Y int
+}
+
+// Size is the width and height of a board. Labels and GTP vertices number
+// rows from the bottom edge, so converting them needs the height.
+type Size struct {
+	Width, Height int
+}
+
+// Contains reports whether p is on a board of this size.
+func (s Size) Contains(p Point) bool {
+	return p.X >= 0 && p.X < s.Width && p.Y >= 0 && p.Y < s.Height
+}
+
+// Column returns the label of column x: a letter, or two letters (AA,
+// AB, ...) past Z.
+func Column(x int) string {
+	n := len(Columns)
+	if x < n {
+		return Columns[x : x+1]
+	}
+	return Columns[x/n-1:x/n] + Columns[x%n:x%n+1]
+}
+
+// ParseColumn is the inverse of Column. It ignores case.
+func ParseColumn(label string) (int, bool) {
+	label = strings.ToUpper(label)
+	n := len(Columns)
+	switch len(label) {
+	case 1:
+		x := strings.IndexByte(Columns, label[0])
+		return x, x >= 0
+	case 2:
+		first := strings.IndexByte(Columns, label[0])
+		second := strings.IndexByte(Columns, label[1])
+		if first < 0 || second < 0 {
+			return 0, false
+		}
+		return (first+1)*n + second, true
+	}
+	return 0, false
+}
+
+// Row returns the label of row y, counting from 1 at the bottom edge.
+func (s Size) Row(y int) int {
+	return s.Height - y
+}
+
+// Label returns p's column and row label, such as "D4" or "AB30".
+func (s Size) Label(p Point) string {
+	return Column(p.X) + strconv.Itoa(s.Row(p.Y))
+}
+
+// ParseLabel is the inverse of Label. It ignores case.
+func (s Size) ParseLabel(label string) (Point, error) {
+	split := strings.IndexAny(label, "0123456789")
+	if split <= 0 {
+		return Point{}, fmt.Errorf("%w %q", ErrInvalid, label)
+	}
+	x, ok := ParseColumn(label[:split])
+	row, err := strconv.Atoi(label[split:])
+	if !ok || err != nil {
+		return Point{}, fmt.Errorf("%w %q", ErrInvalid, label)
+	}
+
+	p := Point{x, s.Height - row}
+	if !s.Contains(p) {
+		return Point{}, fmt.Errorf("%w: %q", ErrOffBoard, label)
+	}
+	return p, nil
+}
+
+// Vertex returns the GTP vertex of p, which is its label, or "pass" for
+// a nil point.
+func (s Size) Vertex(p *Point) string {
+	if p == nil {
+		return "pass"
+	}
+	return s.Label(*p)
+}
+
+// ParseVertex is the inverse of Vertex. pass is true for "pass".
+func (s Size) ParseVertex(vertex string) (p Point, pass bool, err error) {
+	if strings.EqualFold(vertex, "pass") {
+		return Point{}, true, nil
+	}
+	p, err = s.ParseLabel(vertex)
+	return p, false, err
+}
+
+// SGF returns the SGF point of p, such as "dd". Columns and rows both
+// count from the top left, a to z then A to Z.
+func SGF(p Point) string {
+	return string([]byte{sgfLetter(p.X), sgfLetter(p.Y)})
+}
+
+// ParseSGF is the inverse of SGF. Both the empty value and, on boards up
+// to 19x19, "tt" are passes.
+func (s Size) ParseSGF(value string) (p Point, pass bool, err error) {
+	if value == "" || (value == "tt" && s.Width <= 19 && s.Height <= 19) {
+		return Point{}, true, nil
+	}
+	if len(value) != 2 {
+		return Point{}, false, fmt.Errorf("%w %q", ErrInvalid, value)
+	}
+
+	x, okX := sgfCoordinate(value[0])
+	y, okY := sgfCoordinate(value[1])
+	p = Point{x, y}
+	if !okX || !okY {
+		return Point{}, false, fmt.Errorf("%w %q", ErrInvalid, value)
+	}
+	if !s.Contains(p) {
+		return Point{}, false, fmt.Errorf("%w: %q", ErrOffBoard, value)
+	}
+	return p, false, nil
+}
+
+// sgfLetter maps 0..25 to 'a'..'z' and 26..51 to 'A'..'Z'.
+func sgfLetter(i int) byte {
+	if i < 26 {
+		return byte('a' + i)
+	}
+	return byte('A' + i - 26)
+}
+
+// sgfCoordinate maps 'a'..'z' to 0..25 and 'A'..'Z' to 26..51.
+func sgfCoordinate(c byte) (int, bool) {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a'), true
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 26, true
+	}
+	return 0, false
+}
@@ -0,0 +1,110 @@
+package game
+
+import "errors"
+
+var (
+	ErrNotScoring   = errors.New("game is not in the scoring phase")
+	ErrScoringPhase = errors.New("game is in the scoring phase")
+)
+
+// startScoring begins the phase in which the players agree on dead
+// stones. Every stone starts out alive.
+func (g *Game) startScoring() {
+	g.Scoring = true
+	g.DeadStones = make(map[Point]bool)
+	g.Accepted = make(map[Color]bool)
+}
+
+// ToggleDead marks the group at p dead, or alive again if it was marked
+// dead. Any acceptance of the previous marking is withdrawn.
+func (g *Game) ToggleDead(p Point) error {
+	if !g.Scoring {
+		return ErrNotScoring
+	}
+	if !g.Board.IsValidPoint(p) || g.Board.GetColor(p) == Empty {
+		return ErrInvalidMove
+	}
+
+	dead := !g.DeadStones[p]
+	for _, stone := range g.Board.GetGroup(p) {
+		if dead {
+			g.DeadStones[stone] = true
+		} else {
+			delete(g.DeadStones, stone)
+		}
+	}
+	g.Accepted = make(map[Color]bool)
+	return nil
+}
+
+// AcceptScore records that color agrees with the current marking. The
+// game ends once both players have accepted it.
+func (g *Game) AcceptScore(color Color) error {
+	if !g.Scoring {
+		return ErrNotScoring
+	}
+
+	g.Accepted[color] = true
+	if g.Accepted[Black] && g.Accepted[White] {
+		g.Scoring = false
+		g.EndGame()
+	}
+	return nil
+}
+
+// ResumePlay ends the scoring phase because color disputes the status of
+// some stones. As under Japanese rules, color's opponent moves first.
+func (g *Game) ResumePlay(color Color) error {
+	if !g.Scoring {
+		return ErrNotScoring
+	}
+
+	g.Scoring = false
+	g.DeadStones = nil
+	g.Accepted = nil
+	g.Passed[Black] = false
+	g.Passed[White] = false
+	g.CurrentTurn = OpponentColor(color)
+	return nil
+}
+
+// DeadStoneList returns the stones marked dead, in row order.
+func (g *Game) DeadStoneList() []Point {
+	stones := make([]Point, 0, len(g.DeadStones))
+	for p := range g.DeadStones {
+		stones = append(stones, p)
+	}
+	sortPoints(stones)
+	return stones
+}
+
+// withoutDeadStones returns a copy of board with the dead stones removed
+// and counted as prisoners for the opponent.
+func withoutDeadStones(board *Board, dead map[Point]bool) *Board {
+	clone := board.Clone()
+	for p := range dead {
+		if color := clone.GetColor(p); color != Empty {
+			clone.SetStone(p, Empty)
+			clone.Captures[OpponentColor(color)]++
+		}
+	}
+	return clone
+}
+
+func newDeadStoneMarker(board *Board, dead map[Point]bool) *DeadStoneMarker {
+	marker := &DeadStoneMarker{
+		Groups: make([][]Point, 0),
+		Stones: make(map[Point]bool, len(dead)),
+	}
+	for p := range dead {
+		if marker.Stones[p] || board.GetColor(p) == Empty {
+			continue
+		}
+		group := board.GetGroup(p)
+		marker.Groups = append(marker.Groups, group)
+		for _, stone := range group {
+			marker.Stones[stone] = true
+		}
+	}
+	return marker
+}
@@ -1,9 +1,11 @@
 package game
 
 import (
+	"context"
 	"log"
 	"math"
 	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -11,21 +13,113 @@ type AI struct {
 	Color      Color
 	Difficulty string
 	Game       *Game
+
+	// Settings for the "mcts" difficulty. Search stops after Playouts
+	// simulations or MoveTime, whichever comes first; if neither is set
+	// and the context has no deadline DefaultPlayouts is used.
+	Playouts int
+	MoveTime time.Duration
+
+	// Threads is the number of goroutines the "hard" and "mcts" searches
+	// use. If zero it is runtime.NumCPU(), or 1 once a seed has been set
+	// so that MCTS is reproducible.
+	Threads int
+
+	// ResignMargin is how many points behind the AI must be, by the
+	// ownership estimate, before it resigns. If zero it never resigns.
+	ResignMargin float64
+
+	rng    *rand.Rand
+	seed   int64
+	seeded bool
+
+	tree     *mctsNode
+	treeHash uint64
+}
+
+// AIOption configures an AI created by NewAI.
+type AIOption func(*AI)
+
+// WithSeed seeds the AI's random choices. The same position, settings
+// and seed then give the same move, except for searches cut short by a
+// deadline or run on several threads.
+func WithSeed(seed int64) AIOption {
+	return func(ai *AI) {
+		ai.SetSeed(seed)
+	}
+}
+
+// WithPlayouts sets the number of MCTS simulations per move.
+func WithPlayouts(playouts int) AIOption {
+	return func(ai *AI) {
+		ai.Playouts = playouts
+	}
+}
+
+// WithThreads sets the number of search goroutines.
+func WithThreads(threads int) AIOption {
+	return func(ai *AI) {
+		ai.Threads = threads
+	}
 }
 
-func init() {
-	// Initialize random seed once at package init
-	rand.Seed(time.Now().UnixNano())
+// WithResignMargin makes the AI resign once it is more than margin
+// points behind.
+func WithResignMargin(margin float64) AIOption {
+	return func(ai *AI) {
+		ai.ResignMargin = margin
+	}
 }
 
-func NewAI(color Color, difficulty string) *AI {
-	return &AI{
+func NewAI(color Color, difficulty string, options ...AIOption) *AI {
+	ai := &AI{
 		Color:      color,
 		Difficulty: difficulty,
 	}
+	ai.seed = time.Now().UnixNano()
+	ai.rng = rand.New(rand.NewSource(ai.seed))
+
+	for _, option := range options {
+		option(ai)
+	}
+	return ai
+}
+
+// SetSeed restarts the AI's random number generator from seed and drops
+// any search tree kept from earlier moves.
+func (ai *AI) SetSeed(seed int64) {
+	ai.seed = seed
+	ai.seeded = true
+	ai.rng = rand.New(rand.NewSource(seed))
+	ai.tree = nil
+}
+
+// Seed returns the seed of the AI's random number generator. Without
+// WithSeed or SetSeed it is taken from the clock, and can be passed to
+// WithSeed later to replay the AI's choices.
+func (ai *AI) Seed() int64 {
+	return ai.seed
+}
+
+// Name returns the AI's difficulty, which is the name it is registered
+// under.
+func (ai *AI) Name() string {
+	return ai.Difficulty
+}
+
+// GenerateMove plays color in g within ctx's deadline.
+func (ai *AI) GenerateMove(ctx context.Context, g *Game, color Color) *Point {
+	ai.Color = color
+	return ai.GetMoveContext(ctx, g)
 }
 
 func (ai *AI) GetMove(game *Game) *Point {
+	return ai.GetMoveContext(context.Background(), game)
+}
+
+// GetMoveContext is GetMove with a context. When ctx is done the search
+// stops and the best move found so far is returned.
+func (ai *AI) GetMoveContext(ctx context.Context, game *Game) *Point {
 	ai.Game = game
 	
 	log.Printf("AI GetMove called - Color: %s, Difficulty: %s, CurrentTurn: %s", 
@@ -36,17 +130,19 @@ func (ai *AI) GetMove(game *Game) *Point {
 	case "random":
 		move = ai.getRandomMove()
 	case "easy":
-		move = ai.getEasyMove()
+		move = ai.getEasyMove(ctx)
 	case "medium":
-		move = ai.getMediumMove()
+		move = ai.getMediumMove(ctx)
 	case "hard":
-		move = ai.getHardMove()
+		move = ai.getHardMove(ctx)
+	case "mcts":
+		move = ai.getMCTSMove(ctx)
 	default:
-		move = ai.getEasyMove()
+		move = ai.getEasyMove(ctx)
 	}
 	
 	if move != nil {
-		log.Printf("AI selected move at (%d, %d)", move.X, move.Y)
+		log.Printf("AI selected move at %s", game.Board.Label(*move))
 	} else {
 		log.Printf("AI is passing (no valid moves)")
 	}
@@ -54,20 +150,38 @@ func (ai *AI) GetMove(game *Game) *Point {
 	return move
 }
 
+// candidateMoves lists color's legal moves outside pass-alive territory,
+// where a stone can only lose points.
+func (ai *AI) candidateMoves(color Color) []Point {
+	moves := ai.Game.GetValidMoves(color)
+	settled := PassAliveTerritory(ai.Game.Board)
+	if len(settled) == 0 {
+		return moves
+	}
+
+	candidates := moves[:0]
+	for _, p := range moves {
+		if _, ok := settled[p]; !ok {
+			candidates = append(candidates, p)
+		}
+	}
+	return candidates
+}
+
 func (ai *AI) getRandomMove() *Point {
-	validMoves := ai.Game.GetValidMoves(ai.Color)
+	validMoves := ai.candidateMoves(ai.Color)
 	log.Printf("Random AI: Found %d valid moves", len(validMoves))
 	
 	if len(validMoves) == 0 {
 		return nil
 	}
 	
-	randomIndex := rand.Intn(len(validMoves))
+	randomIndex := ai.rng.Intn(len(validMoves))
 	return &validMoves[randomIndex]
 }
 
-func (ai *AI) getEasyMove() *Point {
-	validMoves := ai.Game.GetValidMoves(ai.Color)
+func (ai *AI) getEasyMove(ctx context.Context) *Point {
+	validMoves := ai.candidateMoves(ai.Color)
 	if len(validMoves) == 0 {
 		return nil
 	}
@@ -76,6 +190,9 @@ func (ai *AI) getEasyMove() *Point {
 	bestScore := -1000
 	
 	for _, move := range validMoves {
+		if bestMove != nil && ctx.Err() != nil {
+			break
+		}
 		score := ai.evaluateMove(move)
 		if score > bestScore {
 			bestScore = score
@@ -86,8 +203,8 @@ func (ai *AI) getEasyMove() *Point {
 	return bestMove
 }
 
-func (ai *AI) getMediumMove() *Point {
-	validMoves := ai.Game.GetValidMoves(ai.Color)
+func (ai *AI) getMediumMove(ctx context.Context) *Point {
+	validMoves := ai.candidateMoves(ai.Color)
 	if len(validMoves) == 0 {
 		return nil
 	}
@@ -95,6 +212,9 @@ func (ai *AI) getMediumMove() *Point {
 	var candidates []ScoredMove
 	
 	for _, move := range validMoves {
+		if len(candidates) > 0 && ctx.Err() != nil {
+			break
+		}
 		score := ai.evaluateMoveAdvanced(move)
 		candidates = append(candidates, ScoredMove{Move: move, Score: score})
 	}
@@ -105,31 +225,68 @@ func (ai *AI) getMediumMove() *Point {
 	topMoves := candidates[:topCount]
 	
 	if len(topMoves) > 0 {
-		selected := topMoves[rand.Intn(len(topMoves))]
+		selected := topMoves[ai.rng.Intn(len(topMoves))]
 		return &selected.Move
 	}
 	
 	return &validMoves[0]
 }
 
-func (ai *AI) getHardMove() *Point {
-	validMoves := ai.Game.GetValidMoves(ai.Color)
+// getHardMove searches every move with minimax, spread over Threads
+// goroutines. Moves are searched in order of their quick evaluation, so
+// if ctx is done early the best move so far is among the likeliest ones.
+func (ai *AI) getHardMove(ctx context.Context) *Point {
+	validMoves := ai.candidateMoves(ai.Color)
 	if len(validMoves) == 0 {
 		return nil
 	}
-	
-	var bestMove *Point
+
+	candidates := make([]ScoredMove, len(validMoves))
+	for i, move := range validMoves {
+		candidates[i] = ScoredMove{Move: move, Score: float64(ai.getPositionScore(move))}
+	}
+	sortMovesByScore(candidates)
+
+	var mu sync.Mutex
+	best := -1
 	bestScore := math.Inf(-1)
-	
-	for _, move := range validMoves {
-		score := ai.minimax(move, 3, math.Inf(-1), math.Inf(1), true)
-		if score > bestScore {
-			bestScore = score
-			bestMove = &move
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < ai.threads(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				score := ai.minimax(ctx, candidates[i].Move, 3, math.Inf(-1), math.Inf(1), true)
+				if ctx.Err() != nil {
+					continue
+				}
+				mu.Lock()
+				if score > bestScore || (score == bestScore && i < best) {
+					bestScore = score
+					best = i
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range candidates {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
 		}
 	}
-	
-	return bestMove
+	close(jobs)
+	wg.Wait()
+
+	if best < 0 {
+		best = 0
+	}
+	return &candidates[best].Move
 }
 
 func (ai *AI) evaluateMove(move Point) int {
@@ -163,8 +320,8 @@ func (ai *AI) evaluateMove(move Point) int {
 	}
 	
 	opponent := OpponentColor(ai.Color)
-	for x := 0; x < tempGame.Board.Size; x++ {
-		for y := 0; y < tempGame.Board.Size; y++ {
+	for x := 0; x < tempGame.Board.Width; x++ {
+		for y := 0; y < tempGame.Board.Height; y++ {
 			p := Point{x, y}
 			if tempGame.Board.GetColor(p) == opponent {
 				oppGroup := tempGame.Board.GetGroup(p)
@@ -221,7 +378,10 @@ func (ai *AI) evaluateMoveAdvanced(move Point) float64 {
 	return score
 }
 
-func (ai *AI) minimax(move Point, depth int, alpha, beta float64, maximizing bool) float64 {
+func (ai *AI) minimax(ctx context.Context, move Point, depth int, alpha, beta float64, maximizing bool) float64 {
+	if ctx.Err() != nil {
+		return 0
+	}
 	if depth == 0 {
 		return ai.evaluateMoveAdvanced(move)
 	}
@@ -252,7 +412,7 @@ func (ai *AI) minimax(move Point, depth int, alpha, beta float64, maximizing boo
 	if maximizing {
 		maxScore := math.Inf(-1)
 		for _, nextMove := range validMoves {
-			score := ai.minimax(nextMove, depth-1, alpha, beta, false)
+			score := ai.minimax(ctx, nextMove, depth-1, alpha, beta, false)
 			maxScore = math.Max(maxScore, score)
 			alpha = math.Max(alpha, score)
 			if beta <= alpha {
@@ -263,7 +423,7 @@ func (ai *AI) minimax(move Point, depth int, alpha, beta float64, maximizing boo
 	} else {
 		minScore := math.Inf(1)
 		for _, nextMove := range validMoves {
-			score := ai.minimax(nextMove, depth-1, alpha, beta, true)
+			score := ai.minimax(ctx, nextMove, depth-1, alpha, beta, true)
 			minScore = math.Min(minScore, score)
 			beta = math.Min(beta, score)
 			if beta <= alpha {
@@ -275,10 +435,14 @@ func (ai *AI) minimax(move Point, depth int, alpha, beta float64, maximizing boo
 }
 
 func (ai *AI) getPositionScore(move Point) int {
-	size := ai.Game.Board.Size
+	board := ai.Game.Board
+	size := 0
+	if board.IsSquare() {
+		size = board.Width
+	}
 	score := 0
 	
-	distToEdge := minInt(move.X, minInt(move.Y, minInt(size-1-move.X, size-1-move.Y)))
+	distToEdge := minInt(move.X, minInt(move.Y, minInt(board.Width-1-move.X, board.Height-1-move.Y)))
 	
 	if size == 19 {
 		if distToEdge <= 2 {
@@ -315,8 +479,8 @@ func (ai *AI) calculateInfluence(board *Board, move Point) float64 {
 	influence := 0.0
 	maxDistance := 5
 	
-	for x := 0; x < board.Size; x++ {
-		for y := 0; y < board.Size; y++ {
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
 			if board.Grid[x][y] == Empty {
 				distance := absInt(x-move.X) + absInt(y-move.Y)
 				if distance <= maxDistance {
@@ -405,10 +569,12 @@ func (ai *AI) evaluateBoardState(board *Board) float64 {
 	aiScore := float64(territory[ai.Color] + captures[ai.Color])
 	oppScore := float64(territory[OpponentColor(ai.Color)] + captures[OpponentColor(ai.Color)])
 	
+	// White gets the game's komi and any handicap compensation.
+	komi := ai.Game.Rules.Komi + ai.Game.compensation()
 	if ai.Color == White {
-		aiScore += 6.5
+		aiScore += komi
 	} else {
-		oppScore += 6.5
+		oppScore += komi
 	}
 	
 	return aiScore - oppScore
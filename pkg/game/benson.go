@@ -0,0 +1,176 @@
+package game
+
+import "sort"
+
+// PassAlive holds the chains of one color that Benson's algorithm proves
+// unconditionally alive, and the regions they control. The opponent
+// cannot capture these chains even if their owner passes every move.
+type PassAlive struct {
+	Color  Color
+	Chains [][]Point
+	// Territory is every point, empty or holding an opponent stone, of
+	// the regions the chains enclose and fully control.
+	Territory []Point
+}
+
+// bensonRegion is a maximal connected set of points not occupied by the
+// color under test.
+type bensonRegion struct {
+	points  []Point
+	empty   []Point
+	borders []*chain
+}
+
+// Benson runs Benson's algorithm for color's chains.
+func Benson(board *Board, color Color) *PassAlive {
+	regions := board.bensonRegions(color)
+
+	alive := make(map[*chain]bool)
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			if c := board.chains[x][y]; c != nil && c.color == color {
+				alive[c] = true
+			}
+		}
+	}
+	live := make(map[*bensonRegion]bool, len(regions))
+	for _, r := range regions {
+		live[r] = true
+	}
+
+	// Drop chains with fewer than two vital regions, then regions that
+	// border a dropped chain, until nothing changes.
+	for changed := true; changed; {
+		changed = false
+		for c := range alive {
+			vital := 0
+			for r := range live {
+				if r.borderedBy(c) && r.vitalTo(c) {
+					vital++
+				}
+			}
+			if vital < 2 {
+				delete(alive, c)
+				changed = true
+			}
+		}
+		for r := range live {
+			for _, c := range r.borders {
+				if !alive[c] {
+					delete(live, r)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	result := &PassAlive{Color: color, Chains: [][]Point{}, Territory: []Point{}}
+	for c := range alive {
+		stones := append([]Point(nil), c.stones...)
+		sortPoints(stones)
+		result.Chains = append(result.Chains, stones)
+	}
+	sort.Slice(result.Chains, func(i, j int) bool {
+		a, b := result.Chains[i][0], result.Chains[j][0]
+		return a.Y < b.Y || a.Y == b.Y && a.X < b.X
+	})
+
+	for r := range live {
+		for _, c := range r.borders {
+			if r.vitalTo(c) {
+				result.Territory = append(result.Territory, r.points...)
+				break
+			}
+		}
+	}
+	sortPoints(result.Territory)
+	return result
+}
+
+// passAliveStones returns the stones of both colors that are
+// unconditionally alive.
+func passAliveStones(board *Board) map[Point]bool {
+	stones := make(map[Point]bool)
+	for _, color := range []Color{Black, White} {
+		for _, c := range Benson(board, color).Chains {
+			for _, p := range c {
+				stones[p] = true
+			}
+		}
+	}
+	return stones
+}
+
+// PassAliveTerritory returns the owner of every point in either color's
+// pass-alive territory.
+func PassAliveTerritory(board *Board) map[Point]Color {
+	territory := make(map[Point]Color)
+	for _, color := range []Color{Black, White} {
+		for _, p := range Benson(board, color).Territory {
+			territory[p] = color
+		}
+	}
+	return territory
+}
+
+func (r *bensonRegion) borderedBy(c *chain) bool {
+	for _, border := range r.borders {
+		if border == c {
+			return true
+		}
+	}
+	return false
+}
+
+// vitalTo reports whether every empty point of the region is a liberty
+// of c.
+func (r *bensonRegion) vitalTo(c *chain) bool {
+	for _, p := range r.empty {
+		if _, ok := c.liberties[p]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Board) bensonRegions(color Color) []*bensonRegion {
+	visited := make(map[Point]bool)
+	regions := []*bensonRegion{}
+
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
+			start := Point{x, y}
+			if visited[start] || b.GetColor(start) == color {
+				continue
+			}
+
+			r := &bensonRegion{}
+			seen := make(map[*chain]bool)
+			visited[start] = true
+			queue := []Point{start}
+			for len(queue) > 0 {
+				p := queue[0]
+				queue = queue[1:]
+				r.points = append(r.points, p)
+				if b.GetColor(p) == Empty {
+					r.empty = append(r.empty, p)
+				}
+
+				for _, n := range b.GetNeighbors(p) {
+					if b.GetColor(n) == color {
+						if c := b.chainAt(n); !seen[c] {
+							seen[c] = true
+							r.borders = append(r.borders, c)
+						}
+					} else if !visited[n] {
+						visited[n] = true
+						queue = append(queue, n)
+					}
+				}
+			}
+			regions = append(regions, r)
+		}
+	}
+	return regions
+}
@@ -1,9 +1,21 @@
 package game
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/coord"
+)
+
+// Board dimensions allowed by the SGF specification.
+const (
+	MinBoardSize = 2
+	MaxBoardSize = 52
 )
 
+var ErrInvalidBoardSize = errors.New("board sides must be between 2 and 52")
+
 type Color int
 
 const (
@@ -12,45 +24,111 @@ const (
 	White
 )
 
+// Point is a board point with Y counting rows from the top edge. It
+// converts to coord.Point for GTP, SGF and label notation.
 type Point struct {
 	X, Y int
 }
 
 type Board struct {
-	Size     int
+	Width    int
+	Height   int
 	Grid     [][]Color
 	LastMove *Point
 	Captures map[Color]int
 	History  []BoardState
 	KoPoint  *Point
+	Hash     uint64
+
+	chains     [][]*chain
+	positions  map[uint64]int
+	situations map[uint64]int
 }
 
+// BoardState is one move, pass or setup in a board's history, kept as
+// the change it made rather than a copy of the board so that long games
+// stay small. Undo reverses it.
 type BoardState struct {
-	Grid     [][]Color
-	Captures map[Color]int
-	Move     *Point
-	Player   Color
+	Move   *Point // nil for a pass or setup
+	Player Color
+
+	// Setup holds, for stones changed outside play, the color each point
+	// had before. It is nil for moves and passes.
+	Setup map[Point]Color
+
+	// Captured are the opponent's stones the move removed, and Lost the
+	// player's own, including Move, when it was a suicide.
+	Captured []Point
+	Lost     []Point
+
+	// The board as it was before the move.
+	Captures [3]int // indexed by Color
+	KoPoint  *Point
+	LastMove *Point
+	Hash     uint64
 }
 
+// NewBoard creates an empty size x size board. It panics if size is not
+// between MinBoardSize and MaxBoardSize.
 func NewBoard(size int) *Board {
-	grid := make([][]Color, size)
+	return NewRectangularBoard(size, size)
+}
+
+// NewRectangularBoard creates an empty board width points wide and height
+// points high. It panics if either side is not between MinBoardSize and
+// MaxBoardSize; check sizes from users with ValidateBoardSize first.
+func NewRectangularBoard(width, height int) *Board {
+	if err := ValidateBoardSize(width, height); err != nil {
+		panic(err)
+	}
+
+	grid := make([][]Color, width)
 	for i := range grid {
-		grid[i] = make([]Color, size)
+		grid[i] = make([]Color, height)
 	}
 
-	return &Board{
-		Size: size,
-		Grid: grid,
+	b := &Board{
+		Width:  width,
+		Height: height,
+		Grid:   grid,
 		Captures: map[Color]int{
 			Black: 0,
 			White: 0,
 		},
-		History: make([]BoardState, 0),
+		positions:  make(map[uint64]int),
+		situations: make(map[uint64]int),
+	}
+	b.rebuildChains()
+
+	return b
+}
+
+// ValidateBoardSize returns ErrInvalidBoardSize unless both sides are
+// between MinBoardSize and MaxBoardSize.
+func ValidateBoardSize(width, height int) error {
+	if width < MinBoardSize || width > MaxBoardSize || height < MinBoardSize || height > MaxBoardSize {
+		return ErrInvalidBoardSize
 	}
+	return nil
+}
+
+// IsSquare reports whether the board is as wide as it is high.
+func (b *Board) IsSquare() bool {
+	return b.Width == b.Height
 }
 
 func (b *Board) IsValidPoint(p Point) bool {
-	return p.X >= 0 && p.X < b.Size && p.Y >= 0 && p.Y < b.Size
+	return p.X >= 0 && p.X < b.Width && p.Y >= 0 && p.Y < b.Height
+}
+
+// Size returns the board's dimensions for coordinate conversions.
+func (b *Board) Size() coord.Size {
+	return coord.Size{Width: b.Width, Height: b.Height}
+}
+
+// Label returns p's column and row label on the board, such as "D4".
+func (b *Board) Label(p Point) string {
+	return b.Size().Label(coord.Point(p))
 }
 
 func (b *Board) GetColor(p Point) Color {
@@ -61,8 +139,20 @@ func (b *Board) GetColor(p Point) Color {
 }
 
 func (b *Board) SetStone(p Point, color Color) {
-	if b.IsValidPoint(p) {
+	if !b.IsValidPoint(p) || b.Grid[p.X][p.Y] == color {
+		return
+	}
+
+	if old := b.Grid[p.X][p.Y]; old != Empty {
+		b.Hash ^= zobristKey(p, old)
+		b.Grid[p.X][p.Y] = Empty
+		b.removeStone(p)
+	}
+
+	if color != Empty {
+		b.Hash ^= zobristKey(p, color)
 		b.Grid[p.X][p.Y] = color
+		b.addStone(p, color)
 	}
 }
 
@@ -80,36 +170,25 @@ func (b *Board) GetNeighbors(p Point) []Point {
 }
 
 func (b *Board) GetGroup(p Point) []Point {
-	color := b.GetColor(p)
-	if color == Empty {
+	c := b.chainAt(p)
+	if c == nil {
 		return []Point{}
 	}
 
-	group := []Point{}
-	visited := make(map[Point]bool)
-	queue := []Point{p}
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-
-		if visited[current] {
-			continue
-		}
-		visited[current] = true
-		group = append(group, current)
-
-		for _, neighbor := range b.GetNeighbors(current) {
-			if !visited[neighbor] && b.GetColor(neighbor) == color {
-				queue = append(queue, neighbor)
-			}
-		}
-	}
-
+	group := make([]Point, len(c.stones))
+	copy(group, c.stones)
 	return group
 }
 
 func (b *Board) GetLiberties(group []Point) []Point {
+	if c := b.chainOf(group); c != nil {
+		result := make([]Point, 0, len(c.liberties))
+		for p := range c.liberties {
+			result = append(result, p)
+		}
+		return result
+	}
+
 	liberties := make(map[Point]bool)
 	
 	for _, stone := range group {
@@ -128,13 +207,16 @@ func (b *Board) GetLiberties(group []Point) []Point {
 }
 
 func (b *Board) HasLiberties(p Point) bool {
-	group := b.GetGroup(p)
-	liberties := b.GetLiberties(group)
-	return len(liberties) > 0
+	return b.LibertyCount(p) > 0
 }
 
 func (b *Board) RemoveGroup(group []Point) int {
 	captured := len(group)
+	if c := b.chainOf(group); c != nil {
+		b.removeChain(c)
+		return captured
+	}
+
 	for _, p := range group {
 		b.SetStone(p, Empty)
 	}
@@ -145,13 +227,12 @@ func (b *Board) CaptureDeadGroups(color Color) int {
 	opponent := OpponentColor(color)
 	totalCaptured := 0
 
-	for x := 0; x < b.Size; x++ {
-		for y := 0; y < b.Size; y++ {
-			p := Point{x, y}
-			if b.GetColor(p) == opponent && !b.HasLiberties(p) {
-				group := b.GetGroup(p)
-				captured := b.RemoveGroup(group)
-				totalCaptured += captured
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
+			c := b.chains[x][y]
+			if c != nil && c.color == opponent && len(c.liberties) == 0 {
+				totalCaptured += len(c.stones)
+				b.removeChain(c)
 			}
 		}
 	}
@@ -159,52 +240,127 @@ func (b *Board) CaptureDeadGroups(color Color) int {
 	return totalCaptured
 }
 
+// SaveState adds a move or pass by player to the history before it is
+// played. A move's captures are added with recordCaptures once known.
 func (b *Board) SaveState(move *Point, player Color) {
-	gridCopy := make([][]Color, b.Size)
-	for i := range gridCopy {
-		gridCopy[i] = make([]Color, b.Size)
-		copy(gridCopy[i], b.Grid[i])
-	}
-
-	capturesCopy := make(map[Color]int)
-	for k, v := range b.Captures {
-		capturesCopy[k] = v
-	}
-
 	b.History = append(b.History, BoardState{
-		Grid:     gridCopy,
-		Captures: capturesCopy,
 		Move:     move,
 		Player:   player,
+		Captures: [3]int{Black: b.Captures[Black], White: b.Captures[White]},
+		KoPoint:  b.KoPoint,
+		LastMove: b.LastMove,
+		Hash:     b.Hash,
 	})
+	b.recordPosition(b.Hash, player)
 }
 
-func (b *Board) IsKo(p Point, color Color) bool {
-	if len(b.History) < 2 {
-		return false
+// SetUp changes stones outside play, setting each point in stones to its
+// color, and adds the change to the history so that Undo can reverse it.
+// player is the one to move, who stays on move.
+func (b *Board) SetUp(stones map[Point]Color, player Color) {
+	b.SaveState(nil, player)
+	state := &b.History[len(b.History)-1]
+	state.Setup = make(map[Point]Color)
+	for p, color := range stones {
+		if old := b.GetColor(p); b.IsValidPoint(p) && old != color {
+			state.Setup[p] = old
+			b.SetStone(p, color)
+		}
 	}
+	b.KoPoint = nil
+}
 
-	tempBoard := b.Clone()
-	tempBoard.SetStone(p, color)
-	tempBoard.CaptureDeadGroups(color)
+// recordCaptures notes the stones the last move in the history removed.
+func (b *Board) recordCaptures(captured, lost []Point) {
+	state := &b.History[len(b.History)-1]
+	state.Captured = captured
+	state.Lost = lost
+}
 
-	previousState := b.History[len(b.History)-2]
-	
-	for x := 0; x < b.Size; x++ {
-		for y := 0; y < b.Size; y++ {
-			if tempBoard.Grid[x][y] != previousState.Grid[x][y] {
-				return false
-			}
+// Undo takes back the last move, pass or setup in the history and
+// returns it, or nil if there is none.
+func (b *Board) Undo() *BoardState {
+	if len(b.History) == 0 {
+		return nil
+	}
+	state := b.History[len(b.History)-1]
+	b.History = b.History[:len(b.History)-1]
+	b.forgetPosition(state.Hash, state.Player)
+
+	b.unplay(&state, func(p Point, color Color) { b.SetStone(p, color) })
+	b.Captures[Black] = state.Captures[Black]
+	b.Captures[White] = state.Captures[White]
+	b.KoPoint = state.KoPoint
+	b.LastMove = state.LastMove
+	return &state
+}
+
+// InitialGrid returns the stones on the board before the first move in
+// its history, such as handicap or setup stones, by reversing every move
+// on a copy of the grid.
+func (b *Board) InitialGrid() [][]Color {
+	return b.gridBefore(0)
+}
+
+// SetupGrid returns the stones on the board after the last setup in its
+// history, or before its first move if there was none, and the moves and
+// passes played since, for replaying the game as plays alone.
+func (b *Board) SetupGrid() ([][]Color, []BoardState) {
+	start := 0
+	for i, state := range b.History {
+		if state.Setup != nil {
+			start = i + 1
 		}
 	}
-	
-	return true
+	return b.gridBefore(start), b.History[start:]
+}
+
+// gridBefore returns a copy of the grid as it was before History[start],
+// by reversing the changes from there on.
+func (b *Board) gridBefore(start int) [][]Color {
+	grid := make([][]Color, b.Width)
+	for x := range grid {
+		grid[x] = append([]Color(nil), b.Grid[x]...)
+	}
+	for i := len(b.History) - 1; i >= start; i-- {
+		b.unplay(&b.History[i], func(p Point, color Color) { grid[p.X][p.Y] = color })
+	}
+	return grid
+}
+
+// unplay reverses the stones state changed, calling set for each.
+func (b *Board) unplay(state *BoardState, set func(Point, Color)) {
+	for p, color := range state.Setup {
+		set(p, color)
+	}
+	if state.Move == nil {
+		return
+	}
+	for _, p := range state.Lost {
+		set(p, state.Player)
+	}
+	set(*state.Move, Empty)
+	for _, p := range state.Captured {
+		set(p, OpponentColor(state.Player))
+	}
+}
+
+// IsKo reports whether color playing at p would recreate the position
+// from before the opponent's last move.
+func (b *Board) IsKo(p Point, color Color) bool {
+	if len(b.History) < 1 || b.History[len(b.History)-1].Setup != nil {
+		// A position set up with a ko has no move to compare with.
+		return b.KoPoint != nil && *b.KoPoint == p
+	}
+
+	previousState := b.History[len(b.History)-1]
+	return b.HashAfterMove(p, color) == previousState.Hash
 }
 
 func (b *Board) Clone() *Board {
-	gridCopy := make([][]Color, b.Size)
+	gridCopy := make([][]Color, b.Width)
 	for i := range gridCopy {
-		gridCopy[i] = make([]Color, b.Size)
+		gridCopy[i] = make([]Color, b.Height)
 		copy(gridCopy[i], b.Grid[i])
 	}
 
@@ -213,13 +369,28 @@ func (b *Board) Clone() *Board {
 		capturesCopy[k] = v
 	}
 
+	positionsCopy := make(map[uint64]int, len(b.positions))
+	for k, v := range b.positions {
+		positionsCopy[k] = v
+	}
+
+	situationsCopy := make(map[uint64]int, len(b.situations))
+	for k, v := range b.situations {
+		situationsCopy[k] = v
+	}
+
 	clone := &Board{
-		Size:     b.Size,
-		Grid:     gridCopy,
-		LastMove: b.LastMove,
-		Captures: capturesCopy,
-		KoPoint:  b.KoPoint,
+		Width:      b.Width,
+		Height:     b.Height,
+		Grid:       gridCopy,
+		LastMove:   b.LastMove,
+		Captures:   capturesCopy,
+		KoPoint:    b.KoPoint,
+		Hash:       b.Hash,
+		positions:  positionsCopy,
+		situations: situationsCopy,
 	}
+	clone.rebuildChains()
 
 	return clone
 }
@@ -232,8 +403,8 @@ func (b *Board) CountTerritory() map[Color]int {
 
 	visited := make(map[Point]bool)
 
-	for x := 0; x < b.Size; x++ {
-		for y := 0; y < b.Size; y++ {
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
 			p := Point{x, y}
 			if b.GetColor(p) == Empty && !visited[p] {
 				region, owner := b.findTerritoryRegion(p, visited)
@@ -292,33 +463,38 @@ func (b *Board) findTerritoryRegion(start Point, visited map[Point]bool) ([]Poin
 }
 
 func (b *Board) String() string {
-	result := "  "
-	for i := 0; i < b.Size; i++ {
-		result += fmt.Sprintf("%c ", 'A'+i)
+	cell := "%-2s"
+	if b.Width > len(coord.Columns) {
+		cell = "%-3s"
 	}
-	result += "\n"
 
-	for y := 0; y < b.Size; y++ {
-		result += fmt.Sprintf("%2d ", b.Size-y)
-		for x := 0; x < b.Size; x++ {
+	var sb strings.Builder
+	labels := func() {
+		sb.WriteString("   ")
+		for x := 0; x < b.Width; x++ {
+			fmt.Fprintf(&sb, cell, coord.Column(x))
+		}
+	}
+
+	labels()
+	sb.WriteString("\n")
+	for y := 0; y < b.Height; y++ {
+		fmt.Fprintf(&sb, "%2d ", b.Size().Row(y))
+		for x := 0; x < b.Width; x++ {
 			switch b.Grid[x][y] {
 			case Empty:
-				result += ". "
+				fmt.Fprintf(&sb, cell, ".")
 			case Black:
-				result += "● "
+				fmt.Fprintf(&sb, cell, "●")
 			case White:
-				result += "○ "
+				fmt.Fprintf(&sb, cell, "○")
 			}
 		}
-		result += fmt.Sprintf("%d\n", b.Size-y)
+		fmt.Fprintf(&sb, "%d\n", b.Size().Row(y))
 	}
+	labels()
 
-	result += "  "
-	for i := 0; i < b.Size; i++ {
-		result += fmt.Sprintf("%c ", 'A'+i)
-	}
-
-	return result
+	return sb.String()
 }
 
 func OpponentColor(color Color) Color {
@@ -0,0 +1,187 @@
+package game
+
+// chain is a maximal set of connected stones of one color. Board keeps a
+// chain pointer for every occupied point and updates chains and their
+// liberty sets incrementally as stones are placed and removed, so group
+// and liberty queries cost O(chain size) instead of a flood fill.
+type chain struct {
+	color     Color
+	stones    []Point
+	liberties map[Point]struct{}
+}
+
+func (b *Board) chainAt(p Point) *chain {
+	if !b.IsValidPoint(p) {
+		return nil
+	}
+	return b.chains[p.X][p.Y]
+}
+
+// chainOf returns the chain containing exactly the stones of group, or nil
+// if group is not a whole chain on the current board.
+func (b *Board) chainOf(group []Point) *chain {
+	if len(group) == 0 {
+		return nil
+	}
+
+	c := b.chainAt(group[0])
+	if c == nil || len(c.stones) != len(group) {
+		return nil
+	}
+	for _, stone := range group[1:] {
+		if b.chainAt(stone) != c {
+			return nil
+		}
+	}
+	return c
+}
+
+// addStone creates a chain for a stone already written to the grid and
+// merges it with any adjacent chains of the same color.
+func (b *Board) addStone(p Point, color Color) {
+	c := &chain{
+		color:     color,
+		stones:    []Point{p},
+		liberties: make(map[Point]struct{}, 4),
+	}
+	b.chains[p.X][p.Y] = c
+
+	neighbors := b.GetNeighbors(p)
+	for _, n := range neighbors {
+		if b.Grid[n.X][n.Y] == Empty {
+			c.liberties[n] = struct{}{}
+		} else if nc := b.chains[n.X][n.Y]; nc != nil {
+			delete(nc.liberties, p)
+		}
+	}
+
+	for _, n := range neighbors {
+		if nc := b.chains[n.X][n.Y]; nc != nil && nc.color == color && nc != c {
+			c = b.mergeChains(c, nc)
+		}
+	}
+}
+
+// mergeChains joins two chains of the same color, folding the smaller one
+// into the larger, and returns the surviving chain.
+func (b *Board) mergeChains(a, c *chain) *chain {
+	if len(a.stones) < len(c.stones) {
+		a, c = c, a
+	}
+
+	for _, stone := range c.stones {
+		b.chains[stone.X][stone.Y] = a
+	}
+	a.stones = append(a.stones, c.stones...)
+	for liberty := range c.liberties {
+		a.liberties[liberty] = struct{}{}
+	}
+
+	return a
+}
+
+// removeStone drops a stone that has already been cleared from the grid.
+// The rest of its chain is rebuilt, since removing a stone may split it.
+func (b *Board) removeStone(p Point) {
+	c := b.chains[p.X][p.Y]
+	if c == nil {
+		return
+	}
+
+	for _, stone := range c.stones {
+		b.chains[stone.X][stone.Y] = nil
+	}
+
+	for _, n := range b.GetNeighbors(p) {
+		if nc := b.chains[n.X][n.Y]; nc != nil {
+			nc.liberties[p] = struct{}{}
+		}
+	}
+
+	for _, stone := range c.stones {
+		if stone != p {
+			b.addStone(stone, c.color)
+		}
+	}
+}
+
+// removeChain clears every stone of c from the board and returns the
+// freed points as liberties to the surrounding chains.
+func (b *Board) removeChain(c *chain) {
+	for _, stone := range c.stones {
+		b.Hash ^= zobristKey(stone, c.color)
+		b.Grid[stone.X][stone.Y] = Empty
+		b.chains[stone.X][stone.Y] = nil
+	}
+
+	for _, stone := range c.stones {
+		for _, n := range b.GetNeighbors(stone) {
+			if nc := b.chains[n.X][n.Y]; nc != nil {
+				nc.liberties[stone] = struct{}{}
+			}
+		}
+	}
+}
+
+// rebuildChains recomputes all chains from the grid. It is used after the
+// grid has been replaced wholesale, e.g. when cloning or restoring history.
+func (b *Board) rebuildChains() {
+	b.chains = make([][]*chain, b.Width)
+	for x := range b.chains {
+		b.chains[x] = make([]*chain, b.Height)
+	}
+
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
+			if color := b.Grid[x][y]; color != Empty {
+				b.addStone(Point{x, y}, color)
+			}
+		}
+	}
+}
+
+// LibertyCount returns the number of liberties of the chain at p, or 0 if
+// p is empty.
+func (b *Board) LibertyCount(p Point) int {
+	c := b.chainAt(p)
+	if c == nil {
+		return 0
+	}
+	return len(c.liberties)
+}
+
+// IsSuicide reports whether color playing at the empty point p would leave
+// its own chain without liberties and capture nothing.
+func (b *Board) IsSuicide(p Point, color Color) bool {
+	for _, n := range b.GetNeighbors(p) {
+		c := b.chains[n.X][n.Y]
+		if c == nil {
+			return false
+		}
+		if c.color == color && len(c.liberties) > 1 {
+			return false
+		}
+		if c.color != color && len(c.liberties) == 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Play places a stone of color at p and removes any adjacent opponent
+// chains left without liberties. It returns the captured stones.
+func (b *Board) Play(p Point, color Color) []Point {
+	b.SetStone(p, color)
+
+	captured := []Point{}
+	opponent := OpponentColor(color)
+	for _, n := range b.GetNeighbors(p) {
+		c := b.chains[n.X][n.Y]
+		if c != nil && c.color == opponent && len(c.liberties) == 0 {
+			captured = append(captured, c.stones...)
+			b.removeChain(c)
+		}
+	}
+
+	return captured
+}
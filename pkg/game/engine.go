@@ -0,0 +1,177 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrUnknownEngine  = errors.New("unknown engine")
+	ErrEngineResigned = errors.New("engine resigned")
+)
+
+// Capabilities an engine can advertise in its EngineInfo.
+const (
+	// CapabilityTimeBudget means the engine uses all of the time it is
+	// given; others return as soon as they have a move.
+	CapabilityTimeBudget = "time_budget"
+	// CapabilityAnalysis means the engine implements Analyzer.
+	CapabilityAnalysis = "analysis"
+	// CapabilityExternal means moves come from another program.
+	CapabilityExternal = "external"
+)
+
+// Engine generates moves. GenerateMove returns the move color should play
+// in g, or nil to pass. When ctx is done it returns the best move found so
+// far, so a context deadline is the engine's time budget.
+type Engine interface {
+	Name() string
+	GenerateMove(ctx context.Context, g *Game, color Color) *Point
+}
+
+// Analyzer is implemented by engines that can report how they see a
+// position.
+type Analyzer interface {
+	Analyze(ctx context.Context, g *Game, color Color) *Analysis
+}
+
+// Resigner is implemented by engines that can give up a lost game.
+// ShouldResign reports whether color should resign in g instead of
+// moving.
+type Resigner interface {
+	ShouldResign(ctx context.Context, g *Game, color Color) bool
+}
+
+// FallibleEngine is implemented by engines that can resign or fail while
+// choosing a move, such as external programs. TryGenerateMove is
+// GenerateMove returning ErrEngineResigned, or why no move could be
+// chosen, where GenerateMove would pass.
+type FallibleEngine interface {
+	TryGenerateMove(ctx context.Context, g *Game, color Color) (*Point, error)
+}
+
+// Seeder is implemented by engines whose random choices can be made
+// reproducible. Seed reports the seed in use, so that a game played with
+// a clock-based seed can be replayed.
+type Seeder interface {
+	Seed() int64
+	SetSeed(seed int64)
+}
+
+// Analysis is an engine's view of a position from the side to move.
+type Analysis struct {
+	WinRate    float64         `json:"winRate"`
+	Candidates []CandidateMove `json:"candidates"`
+}
+
+// CandidateMove is a move the engine considered. Move is nil for a pass.
+type CandidateMove struct {
+	Move    *Point  `json:"move"`
+	Visits  int     `json:"visits"`
+	WinRate float64 `json:"winRate"`
+}
+
+// EngineInfo describes a registered engine.
+type EngineInfo struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// EngineFactory creates a fresh engine instance, for example one per game.
+type EngineFactory func() Engine
+
+type registeredEngine struct {
+	info    EngineInfo
+	factory EngineFactory
+}
+
+var engineRegistry = struct {
+	sync.RWMutex
+	engines map[string]registeredEngine
+}{engines: make(map[string]registeredEngine)}
+
+func init() {
+	RegisterEngine(EngineInfo{Name: "random", Description: "Plays a random legal move", Capabilities: []string{CapabilityAnalysis}}, aiFactory("random"))
+	RegisterEngine(EngineInfo{Name: "easy", Description: "Greedy evaluation of captures and liberties", Capabilities: []string{CapabilityAnalysis}}, aiFactory("easy"))
+	RegisterEngine(EngineInfo{Name: "medium", Description: "Evaluation with influence and shape", Capabilities: []string{CapabilityAnalysis}}, aiFactory("medium"))
+	RegisterEngine(EngineInfo{Name: "hard", Description: "Minimax with alpha-beta pruning", Capabilities: []string{CapabilityAnalysis}}, aiFactory("hard"))
+	RegisterEngine(EngineInfo{Name: "mcts", Description: "Monte Carlo tree search with RAVE", Capabilities: []string{CapabilityTimeBudget, CapabilityAnalysis}}, aiFactory("mcts"))
+}
+
+func aiFactory(difficulty string) EngineFactory {
+	return func() Engine {
+		return NewAI(Black, difficulty)
+	}
+}
+
+// RegisterEngine makes an engine available by info.Name. Registering a
+// name twice replaces the earlier engine.
+func RegisterEngine(info EngineInfo, factory EngineFactory) {
+	engineRegistry.Lock()
+	defer engineRegistry.Unlock()
+	engineRegistry.engines[info.Name] = registeredEngine{info: info, factory: factory}
+}
+
+// NewEngine creates an instance of the engine registered as name.
+func NewEngine(name string) (Engine, error) {
+	engineRegistry.RLock()
+	registered, ok := engineRegistry.engines[name]
+	engineRegistry.RUnlock()
+
+	if !ok {
+		return nil, ErrUnknownEngine
+	}
+	return registered.factory(), nil
+}
+
+// Engines lists the registered engines sorted by name.
+func Engines() []EngineInfo {
+	engineRegistry.RLock()
+	defer engineRegistry.RUnlock()
+
+	infos := make([]EngineInfo, 0, len(engineRegistry.engines))
+	for _, registered := range engineRegistry.engines {
+		infos = append(infos, registered.info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// ScriptedEngine plays a fixed sequence of moves, passing once the script
+// runs out or its next move is illegal. It is useful for tests and
+// tutorials.
+type ScriptedEngine struct {
+	EngineName string
+	Moves      []*Point
+	next       int
+}
+
+// NewScriptedEngine creates an engine that plays moves in order. A nil
+// move is a pass.
+func NewScriptedEngine(name string, moves ...*Point) *ScriptedEngine {
+	return &ScriptedEngine{EngineName: name, Moves: moves}
+}
+
+// Name returns the engine's name.
+func (s *ScriptedEngine) Name() string {
+	return s.EngineName
+}
+
+// GenerateMove returns the next scripted move.
+func (s *ScriptedEngine) GenerateMove(ctx context.Context, g *Game, color Color) *Point {
+	if s.next >= len(s.Moves) {
+		return nil
+	}
+	move := s.Moves[s.next]
+	s.next++
+
+	if move != nil && g.ValidateMove(*move, color) != nil {
+		return nil
+	}
+	return move
+}
@@ -0,0 +1,140 @@
+package game
+
+import "errors"
+
+var (
+	ErrInvalidHandicap   = errors.New("invalid number of handicap stones")
+	ErrHandicapPlacement = errors.New("handicap stones must be placed on an empty board before the first move")
+)
+
+// HandicapKomi is the customary komi in handicap games: half a point, so
+// that there are no draws.
+const HandicapKomi = 0.5
+
+// HandicapCompensation says how many points White receives for Black's
+// handicap stones under area scoring, where every extra Black stone would
+// otherwise count for Black.
+type HandicapCompensation string
+
+const (
+	// NoCompensation gives White nothing, as in territory scoring.
+	NoCompensation HandicapCompensation = "none"
+	// ChineseCompensation gives White one point per handicap stone.
+	ChineseCompensation HandicapCompensation = "chinese"
+	// AGACompensation gives White one point per handicap stone after
+	// the first.
+	AGACompensation HandicapCompensation = "aga"
+)
+
+// Points returns White's compensation for a handicap of n stones.
+func (c HandicapCompensation) Points(n int) float64 {
+	if n < 2 {
+		return 0
+	}
+	switch c {
+	case ChineseCompensation:
+		return float64(n)
+	case AGACompensation:
+		return float64(n - 1)
+	default:
+		return 0
+	}
+}
+
+// MaxFixedHandicap returns the largest fixed handicap for a board size:
+// nine on odd boards from 9x9 up, four on even boards and 7x7, and none
+// on smaller boards.
+func MaxFixedHandicap(size int) int {
+	switch {
+	case size < 7:
+		return 0
+	case size == 7 || size%2 == 0:
+		return 4
+	default:
+		return 9
+	}
+}
+
+// FixedHandicapPoints returns the star points for a fixed handicap of n
+// stones, in the order given by the GTP specification.
+func FixedHandicapPoints(size, n int) ([]Point, error) {
+	if n < 2 || n > MaxFixedHandicap(size) {
+		return nil, ErrInvalidHandicap
+	}
+
+	edge := 3
+	if size < 13 {
+		edge = 2
+	}
+	low, high, mid := edge, size-1-edge, size/2
+
+	corners := []Point{{low, high}, {high, low}, {low, low}, {high, high}}
+	center := Point{mid, mid}
+	sides := []Point{{low, mid}, {high, mid}, {mid, high}, {mid, low}}
+
+	points := append([]Point{}, corners[:minInt(n, 4)]...)
+	switch n {
+	case 5:
+		points = append(points, center)
+	case 6:
+		points = append(points, sides[:2]...)
+	case 7:
+		points = append(points, sides[:2]...)
+		points = append(points, center)
+	case 8:
+		points = append(points, sides...)
+	case 9:
+		points = append(points, sides...)
+		points = append(points, center)
+	}
+
+	return points, nil
+}
+
+// SetFixedHandicap places n handicap stones on the star points like
+// PlaceHandicap.
+func (g *Game) SetFixedHandicap(n int) error {
+	if !g.Board.IsSquare() {
+		return ErrInvalidHandicap
+	}
+	points, err := FixedHandicapPoints(g.Board.Width, n)
+	if err != nil {
+		return err
+	}
+	return g.PlaceHandicap(points)
+}
+
+// PlaceHandicap places Black handicap stones on freely chosen points,
+// gives White the first move and sets komi to HandicapKomi. The board
+// must be empty.
+func (g *Game) PlaceHandicap(points []Point) error {
+	if len(points) < 2 || len(points) >= g.Board.Width*g.Board.Height {
+		return ErrInvalidHandicap
+	}
+	if len(g.Board.History) > 0 || g.Handicap > 0 {
+		return ErrHandicapPlacement
+	}
+	for x := range g.Board.Grid {
+		for _, color := range g.Board.Grid[x] {
+			if color != Empty {
+				return ErrHandicapPlacement
+			}
+		}
+	}
+
+	seen := make(map[Point]bool, len(points))
+	for _, p := range points {
+		if !g.Board.IsValidPoint(p) || seen[p] {
+			return ErrInvalidMove
+		}
+		seen[p] = true
+	}
+
+	for _, p := range points {
+		g.Board.SetStone(p, Black)
+	}
+	g.Handicap = len(points)
+	g.CurrentTurn = White
+	g.Rules.Komi = HandicapKomi
+	return nil
+}
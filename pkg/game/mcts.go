@@ -0,0 +1,521 @@
+package game
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultPlayouts is the number of simulations the MCTS player runs
+	// per move when neither Playouts nor MoveTime is set.
+	DefaultPlayouts = 3000
+
+	// raveEquivalence controls how quickly node statistics take over from
+	// AMAF statistics: at this many visits both are weighted equally.
+	raveEquivalence = 500.0
+	uctExploration  = 0.2
+
+	// maxCandidates limits the moves reported by Analyze.
+	maxCandidates = 10
+)
+
+// mctsNode is a node of the search tree. Statistics are from the point of
+// view of color, the player whose move led to the node.
+type mctsNode struct {
+	move     *Point
+	color    Color
+	parent   *mctsNode
+	children []*mctsNode
+	expanded bool
+
+	visits     float64
+	wins       float64
+	amafVisits float64
+	amafWins   float64
+}
+
+// simulation is a lightweight game state for descending the tree and
+// running playouts. It enforces simple ko only.
+type simulation struct {
+	board  *Board
+	toMove Color
+	ko     *Point
+	passes int
+	empty  []Point
+}
+
+func newSimulation(board *Board, toMove Color) *simulation {
+	s := &simulation{
+		board:  board.copyPosition(),
+		toMove: toMove,
+		ko:     board.KoPoint,
+	}
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			if board.Grid[x][y] == Empty {
+				s.empty = append(s.empty, Point{x, y})
+			}
+		}
+	}
+	return s
+}
+
+// copyPosition copies the stones, captures and hash of b without its
+// history, for throwaway searches.
+func (b *Board) copyPosition() *Board {
+	gridCopy := make([][]Color, b.Width)
+	for i := range gridCopy {
+		gridCopy[i] = make([]Color, b.Height)
+		copy(gridCopy[i], b.Grid[i])
+	}
+
+	clone := &Board{
+		Width:      b.Width,
+		Height:     b.Height,
+		Grid:       gridCopy,
+		Captures:   map[Color]int{Black: b.Captures[Black], White: b.Captures[White]},
+		Hash:       b.Hash,
+		KoPoint:    b.KoPoint,
+		positions:  make(map[uint64]int),
+		situations: make(map[uint64]int),
+	}
+	clone.rebuildChains()
+	return clone
+}
+
+func (s *simulation) isLegal(p Point) bool {
+	if s.board.Grid[p.X][p.Y] != Empty {
+		return false
+	}
+	if s.ko != nil && *s.ko == p {
+		return false
+	}
+	return !s.board.IsSuicide(p, s.toMove)
+}
+
+// isOwnEye reports whether p is an eye of color that a sensible player
+// would not fill: every neighbor is color, and the diagonals hold at most
+// one opponent stone (none on the edge).
+func (s *simulation) isOwnEye(p Point, color Color) bool {
+	return isSimpleEye(s.board, p, color)
+}
+
+func isSimpleEye(board *Board, p Point, color Color) bool {
+	neighbors := board.GetNeighbors(p)
+	for _, n := range neighbors {
+		if board.Grid[n.X][n.Y] != color {
+			return false
+		}
+	}
+
+	opponent := OpponentColor(color)
+	diagonals := []Point{{p.X - 1, p.Y - 1}, {p.X + 1, p.Y - 1}, {p.X - 1, p.Y + 1}, {p.X + 1, p.Y + 1}}
+	bad, onBoard := 0, 0
+	for _, d := range diagonals {
+		if board.IsValidPoint(d) {
+			onBoard++
+			if board.Grid[d.X][d.Y] == opponent {
+				bad++
+			}
+		}
+	}
+
+	if onBoard < 4 {
+		return bad == 0
+	}
+	return bad <= 1
+}
+
+func (s *simulation) play(p *Point) {
+	color := s.toMove
+	s.toMove = OpponentColor(color)
+
+	if p == nil {
+		s.passes++
+		s.ko = nil
+		return
+	}
+	s.passes = 0
+
+	captured := s.board.Play(*p, color)
+	s.board.Captures[color] += len(captured)
+
+	for i, e := range s.empty {
+		if e == *p {
+			last := len(s.empty) - 1
+			s.empty[i] = s.empty[last]
+			s.empty = s.empty[:last]
+			break
+		}
+	}
+	s.empty = append(s.empty, captured...)
+
+	s.ko = nil
+	if len(captured) == 1 && s.board.LibertyCount(*p) == 1 && len(s.board.chainAt(*p).stones) == 1 {
+		s.ko = &captured[0]
+	}
+}
+
+// randomMove picks a uniformly random legal move that does not fill one
+// of the mover's own eyes, or nil to pass if there is none.
+func (s *simulation) randomMove(rng *rand.Rand) *Point {
+	n := len(s.empty)
+	if n == 0 {
+		return nil
+	}
+
+	start := rng.Intn(n)
+	for i := 0; i < n; i++ {
+		p := s.empty[(start+i)%n]
+		if s.isLegal(p) && !s.isOwnEye(p, s.toMove) {
+			return &p
+		}
+	}
+	return nil
+}
+
+// score returns Black's area score minus White's, counting stones and
+// empty points whose neighbors are all one color. This is exact once a
+// playout has filled everything but eyes.
+func (s *simulation) score(komi float64) float64 {
+	black, white := 0, 0
+	board := s.board
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			switch s.owner(Point{x, y}) {
+			case Black:
+				black++
+			case White:
+				white++
+			}
+		}
+	}
+	return float64(black-white) - komi
+}
+
+// owner returns the color of the stone at p, or of the stones around it
+// if p is empty, or Empty if that is mixed.
+func (s *simulation) owner(p Point) Color {
+	if color := s.board.Grid[p.X][p.Y]; color != Empty {
+		return color
+	}
+	return surroundingColor(s.board, p)
+}
+
+func surroundingColor(board *Board, p Point) Color {
+	owner := Empty
+	for _, n := range board.GetNeighbors(p) {
+		color := board.Grid[n.X][n.Y]
+		if color == Empty || (owner != Empty && color != owner) {
+			return Empty
+		}
+		owner = color
+	}
+	return owner
+}
+
+// getMCTSMove runs UCT search with RAVE from the current position and
+// plays the most visited move.
+func (ai *AI) getMCTSMove(ctx context.Context) *Point {
+	root := ai.search(ctx)
+
+	var best *mctsNode
+	for _, child := range root.children {
+		if best == nil || child.visits > best.visits {
+			best = child
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.move
+}
+
+// search grows the tree for the current position and returns its root.
+// It stops after Playouts simulations, after MoveTime, or when ctx is
+// done, whichever comes first, but always runs at least one simulation.
+// Workers share the tree and use virtual loss to spread out.
+//
+// The tree from the previous search is reused when the current position
+// is one of its nodes.
+func (ai *AI) search(ctx context.Context) *mctsNode {
+	root := ai.reuseTree()
+	if root == nil {
+		root = &mctsNode{color: OpponentColor(ai.Color)}
+	} else {
+		ai.pruneRoot(root)
+	}
+	ai.tree = root
+	ai.treeHash = ai.Game.Board.Hash
+
+	playouts := int64(ai.Playouts)
+	if playouts <= 0 && ai.MoveTime <= 0 {
+		if _, ok := ctx.Deadline(); !ok {
+			playouts = DefaultPlayouts
+		}
+	}
+	if ai.MoveTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ai.MoveTime)
+		defer cancel()
+	}
+
+	komi := ai.Game.Rules.Komi + ai.Game.compensation()
+
+	var mu sync.Mutex
+	var started int64
+	var wg sync.WaitGroup
+	for i := 0; i < ai.threads(); i++ {
+		rng := rand.New(rand.NewSource(ai.rng.Int63()))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				n := atomic.AddInt64(&started, 1)
+				if playouts > 0 && n > playouts {
+					return
+				}
+				if n > 1 && ctx.Err() != nil {
+					return
+				}
+				ai.runSimulation(root, &mu, rng, komi)
+			}
+		}()
+	}
+	wg.Wait()
+	return root
+}
+
+// Analyze runs an MCTS search for color and reports the win rate at the
+// root with the most visited moves, whatever the AI's difficulty.
+func (ai *AI) Analyze(ctx context.Context, g *Game, color Color) *Analysis {
+	ai.Game = g
+	ai.Color = color
+	root := ai.search(ctx)
+
+	analysis := &Analysis{WinRate: 0.5}
+	if root.visits > 0 {
+		analysis.WinRate = 1 - root.wins/root.visits
+	}
+
+	children := append([]*mctsNode(nil), root.children...)
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].visits > children[j].visits
+	})
+	for _, child := range children {
+		if child.visits == 0 || len(analysis.Candidates) == maxCandidates {
+			break
+		}
+		analysis.Candidates = append(analysis.Candidates, CandidateMove{
+			Move:    child.move,
+			Visits:  int(child.visits),
+			WinRate: child.wins / child.visits,
+		})
+	}
+	return analysis
+}
+
+// reuseTree looks for the current position among the nodes up to two
+// plies below the previous root and, if found, makes it the new root.
+func (ai *AI) reuseTree() *mctsNode {
+	if ai.tree == nil || len(ai.Game.Board.History) == 0 {
+		return nil
+	}
+
+	board := ai.Game.Board
+	candidates := []*mctsNode{ai.tree}
+	for depth := 0; depth < 2; depth++ {
+		next := []*mctsNode{}
+		for _, node := range candidates {
+			next = append(next, node.children...)
+		}
+		candidates = next
+	}
+
+	// The previous search started from treeHash, so replaying the last
+	// two moves from it must reach the current board.
+	history := board.History
+	if len(history) < 2 || history[len(history)-2].Hash != ai.treeHash {
+		return nil
+	}
+	first, second := history[len(history)-2], history[len(history)-1]
+	if first.Setup != nil || second.Setup != nil {
+		return nil
+	}
+
+	for _, node := range candidates {
+		if node.parent == nil || node.color == ai.Color {
+			continue
+		}
+		if samePointer(node.move, second.Move) && samePointer(node.parent.move, first.Move) {
+			node.parent = nil
+			return node
+		}
+	}
+	return nil
+}
+
+// pruneRoot drops children of a reused root that the game's rules forbid.
+// Below the root the search only knows simple ko, so a move it expanded
+// there may repeat an earlier position.
+func (ai *AI) pruneRoot(root *mctsNode) {
+	children := root.children[:0]
+	for _, child := range root.children {
+		if child.move == nil || ai.Game.ValidateMove(*child.move, ai.Color) == nil {
+			children = append(children, child)
+		}
+	}
+	root.children = children
+}
+
+func (ai *AI) threads() int {
+	if ai.Threads > 0 {
+		return ai.Threads
+	}
+	if ai.seeded {
+		return 1
+	}
+	return runtime.NumCPU()
+}
+
+func samePointer(a, b *Point) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// runSimulation performs one select-expand-playout-backup cycle. The
+// tree is only touched while holding mu; the playout runs unlocked.
+//
+// Visits are counted on the way down, so until the result is backed up a
+// node in progress looks like a loss to other workers (virtual loss).
+func (ai *AI) runSimulation(root *mctsNode, mu *sync.Mutex, rng *rand.Rand, komi float64) {
+	sim := newSimulation(ai.Game.Board, ai.Color)
+	played := make(map[Point]Color)
+
+	mu.Lock()
+	node := root
+	node.visits++
+	path := []*mctsNode{root}
+	descend := func(child *mctsNode) {
+		node = child
+		node.visits++
+		path = append(path, node)
+		if node.move != nil {
+			if _, ok := played[*node.move]; !ok {
+				played[*node.move] = node.color
+			}
+		}
+		sim.play(node.move)
+	}
+
+	for node.expanded && len(node.children) > 0 && sim.passes < 2 {
+		descend(ai.selectChild(node))
+	}
+	if sim.passes < 2 && !node.expanded {
+		ai.expand(node, sim, node == root)
+		if len(node.children) > 0 {
+			descend(node.children[rng.Intn(len(node.children))])
+		}
+	}
+	mu.Unlock()
+
+	maxMoves := 3 * sim.board.Width * sim.board.Height
+	for i := 0; i < maxMoves && sim.passes < 2; i++ {
+		move := sim.randomMove(rng)
+		if move != nil {
+			if _, ok := played[*move]; !ok {
+				played[*move] = sim.toMove
+			}
+		}
+		sim.play(move)
+	}
+	blackWins := sim.score(komi) > 0
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, n := range path {
+		if (n.color == Black) == blackWins {
+			n.wins++
+		}
+
+		for _, child := range n.children {
+			if child.move == nil || played[*child.move] != child.color {
+				continue
+			}
+			child.amafVisits++
+			if (child.color == Black) == blackWins {
+				child.amafWins++
+			}
+		}
+	}
+}
+
+// expand creates a child for every legal move at node. At the root the
+// game's own rules decide legality, so superko is respected. Filling an
+// own eye is never considered, and passing is only considered when the
+// opponent has just passed or nothing else is left.
+func (ai *AI) expand(node *mctsNode, sim *simulation, isRoot bool) {
+	node.expanded = true
+	color := sim.toMove
+
+	var moves []Point
+	if isRoot {
+		moves = ai.candidateMoves(color)
+	} else {
+		for _, p := range sim.empty {
+			if sim.isLegal(p) {
+				moves = append(moves, p)
+			}
+		}
+	}
+
+	for i := range moves {
+		if sim.isOwnEye(moves[i], color) {
+			continue
+		}
+		move := moves[i]
+		node.children = append(node.children, &mctsNode{move: &move, color: color, parent: node})
+	}
+
+	if len(node.children) == 0 || sim.passes > 0 {
+		node.children = append(node.children, &mctsNode{color: color, parent: node})
+	}
+}
+
+// selectChild picks the child with the best blend of its own win rate and
+// its AMAF win rate, plus a UCT exploration bonus.
+func (ai *AI) selectChild(node *mctsNode) *mctsNode {
+	var best *mctsNode
+	bestValue := math.Inf(-1)
+	logVisits := math.Log(node.visits + 1)
+
+	for _, child := range node.children {
+		value := child.value() + uctExploration*math.Sqrt(logVisits/(child.visits+1))
+		if value > bestValue {
+			bestValue = value
+			best = child
+		}
+	}
+	return best
+}
+
+func (n *mctsNode) value() float64 {
+	winRate := 0.5
+	if n.visits > 0 {
+		winRate = n.wins / n.visits
+	}
+	if n.amafVisits == 0 {
+		return winRate
+	}
+
+	amafRate := n.amafWins / n.amafVisits
+	beta := math.Sqrt(raveEquivalence / (3*n.visits + raveEquivalence))
+	return (1-beta)*winRate + beta*amafRate
+}
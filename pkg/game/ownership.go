@@ -0,0 +1,189 @@
+package game
+
+import (
+	"context"
+	"math/rand"
+)
+
+const (
+	// DefaultOwnershipPlayouts is the number of playouts EstimateOwnership
+	// runs when asked for none.
+	DefaultOwnershipPlayouts = 200
+
+	// ownershipThreshold is how far from zero a point's ownership must be,
+	// that is in how many more playouts one color owns it, for the point
+	// to count as that color's.
+	ownershipThreshold = 0.3
+)
+
+// Ownership is who ends up owning each point in random playouts from a
+// position: 1 if Black owns a point in every playout, -1 if White does,
+// and in between when it varies.
+type Ownership struct {
+	// Values is indexed [x][y], like Board.Grid.
+	Values   [][]float64
+	Playouts int
+}
+
+// EstimateOwnership plays g's position out to the end with random moves
+// that never fill the mover's own eyes, and averages the owner of every
+// point. It stops after playouts games, or DefaultOwnershipPlayouts if
+// playouts is not positive, or when ctx is done, but always plays at
+// least one.
+func EstimateOwnership(ctx context.Context, g *Game, playouts int, rng *rand.Rand) *Ownership {
+	if playouts <= 0 {
+		playouts = DefaultOwnershipPlayouts
+	}
+
+	width, height := g.Board.Width, g.Board.Height
+	totals := make([][]int, width)
+	for x := range totals {
+		totals[x] = make([]int, height)
+	}
+
+	o := &Ownership{}
+	for o.Playouts < playouts && (o.Playouts == 0 || ctx.Err() == nil) {
+		sim := newSimulation(g.Board, g.CurrentTurn)
+		maxMoves := 3 * width * height
+		for i := 0; i < maxMoves && sim.passes < 2; i++ {
+			sim.play(sim.randomMove(rng))
+		}
+
+		for x := 0; x < width; x++ {
+			for y := 0; y < height; y++ {
+				switch sim.owner(Point{x, y}) {
+				case Black:
+					totals[x][y]++
+				case White:
+					totals[x][y]--
+				}
+			}
+		}
+		o.Playouts++
+	}
+
+	o.Values = make([][]float64, width)
+	for x := range o.Values {
+		o.Values[x] = make([]float64, height)
+		for y := range o.Values[x] {
+			o.Values[x][y] = float64(totals[x][y]) / float64(o.Playouts)
+		}
+	}
+	return o
+}
+
+// At returns the ownership of p.
+func (o *Ownership) At(p Point) float64 {
+	return o.Values[p.X][p.Y]
+}
+
+// Owner returns the color that owns p in clearly more playouts than the
+// other, or Empty if neither does.
+func (o *Ownership) Owner(p Point) Color {
+	switch v := o.At(p); {
+	case v >= ownershipThreshold:
+		return Black
+	case v <= -ownershipThreshold:
+		return White
+	}
+	return Empty
+}
+
+// DeadStones suggests which stones on board are dead: the groups whose
+// points mostly end up owned by the opponent. Groups that are pass-alive
+// or in seki are never suggested.
+func (o *Ownership) DeadStones(board *Board) map[Point]bool {
+	alive := passAliveStones(board)
+	for p := range sekiStones(board) {
+		alive[p] = true
+	}
+
+	dead := make(map[Point]bool)
+	visited := make(map[Point]bool)
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			p := Point{x, y}
+			color := board.GetColor(p)
+			if color == Empty || visited[p] {
+				continue
+			}
+
+			group := board.GetGroup(p)
+			total := 0.0
+			for _, stone := range group {
+				visited[stone] = true
+				total += o.At(stone)
+			}
+			if color == White {
+				total = -total
+			}
+			if alive[p] || total/float64(len(group)) > -ownershipThreshold {
+				continue
+			}
+			for _, stone := range group {
+				dead[stone] = true
+			}
+		}
+	}
+	return dead
+}
+
+// Score is a provisional score for g: the suggested dead stones are
+// removed and every empty point counts for the color that owns it.
+func (o *Ownership) Score(g *Game) *Score {
+	board := withoutDeadStones(g.Board, o.DeadStones(g.Board))
+
+	score := &Score{
+		Territory: map[Color]int{Black: 0, White: 0},
+		Captures:  board.Captures,
+		Komi:      g.Rules.Komi,
+		Method:    g.Rules.ScoringMethod,
+	}
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			p := Point{x, y}
+			if board.GetColor(p) != Empty {
+				continue
+			}
+			if owner := o.Owner(p); owner != Empty {
+				score.Territory[owner]++
+			}
+		}
+	}
+
+	if score.Method == JapaneseScoring {
+		score.calculateJapaneseScore(board)
+	} else {
+		score.calculateChineseScore(board)
+	}
+	score.Compensation = g.compensation()
+	score.White += score.Compensation
+	score.determineWinner()
+	return score
+}
+
+// SuggestDeadStones replaces the dead stone marking with the one o
+// suggests, withdrawing any acceptance of the previous marking.
+func (g *Game) SuggestDeadStones(o *Ownership) error {
+	if !g.Scoring {
+		return ErrNotScoring
+	}
+	g.DeadStones = o.DeadStones(g.Board)
+	g.Accepted = make(map[Color]bool)
+	return nil
+}
+
+// ShouldResign reports whether the ownership estimate puts color more
+// than ResignMargin points behind in g. An AI without a ResignMargin
+// never resigns.
+func (ai *AI) ShouldResign(ctx context.Context, g *Game, color Color) bool {
+	if ai.ResignMargin <= 0 {
+		return false
+	}
+
+	lead := EstimateOwnership(ctx, g, DefaultOwnershipPlayouts, ai.rng).Score(g).Difference
+	if color == White {
+		lead = -lead
+	}
+	return lead < -ai.ResignMargin
+}
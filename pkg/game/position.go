@@ -0,0 +1,240 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/coord"
+)
+
+var (
+	ErrInvalidDiagram  = errors.New("invalid board diagram")
+	ErrInvalidPosition = errors.New("invalid position string")
+)
+
+// Diagram returns the board as rows of X (Black), O (White) and . (empty),
+// top row first, one per line. ParseDiagram reads it back.
+func (b *Board) Diagram() string {
+	var sb strings.Builder
+	for y := 0; y < b.Height; y++ {
+		for x := 0; x < b.Width; x++ {
+			sb.WriteByte(diagramGlyph(b.Grid[x][y]))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// ParseDiagram reads a board from a diagram: either the rows Diagram
+// writes, or what String prints, with ● and ○ for the stones and column
+// and row labels around them. GTP showboard output with + on the star
+// points is read as well. The board's size is that of the diagram.
+func ParseDiagram(text string) (*Board, error) {
+	var rows [][]Color
+	var labels []int
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		label := 0
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			label = n
+			fields = fields[1:]
+			if len(fields) > 0 {
+				if _, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+					fields = fields[:len(fields)-1]
+				}
+			}
+		}
+
+		row, ok := parseDiagramRow(strings.Join(fields, ""))
+		if !ok {
+			// Column labels above and below the board.
+			if label == 0 && len(fields) > 0 && fields[0] == coord.Column(0) {
+				continue
+			}
+			return nil, fmt.Errorf("%w: line %q", ErrInvalidDiagram, line)
+		}
+		if len(rows) > 0 && len(row) != len(rows[0]) {
+			return nil, fmt.Errorf("%w: rows of %d and %d points", ErrInvalidDiagram, len(rows[0]), len(row))
+		}
+		rows = append(rows, row)
+		labels = append(labels, label)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no rows", ErrInvalidDiagram)
+	}
+	width, height := len(rows[0]), len(rows)
+	if err := ValidateBoardSize(width, height); err != nil {
+		return nil, err
+	}
+
+	b := NewRectangularBoard(width, height)
+	for y, row := range rows {
+		if labels[y] != 0 && labels[y] != b.Size().Row(y) {
+			return nil, fmt.Errorf("%w: row %d labelled %d", ErrInvalidDiagram, b.Size().Row(y), labels[y])
+		}
+		for x, color := range row {
+			b.SetStone(Point{x, y}, color)
+		}
+	}
+	return b, nil
+}
+
+func parseDiagramRow(cells string) ([]Color, bool) {
+	if cells == "" {
+		return nil, false
+	}
+	var row []Color
+	for _, r := range cells {
+		switch r {
+		case 'X', '●':
+			row = append(row, Black)
+		case 'O', '○':
+			row = append(row, White)
+		case '.', '+':
+			row = append(row, Empty)
+		default:
+			return nil, false
+		}
+	}
+	return row, true
+}
+
+func diagramGlyph(color Color) byte {
+	switch color {
+	case Black:
+		return 'X'
+	case White:
+		return 'O'
+	}
+	return '.'
+}
+
+// Position returns g's position on one line: the rows from the top
+// separated by /, with runs of empty points as numbers, then the player
+// to move (b or w), the ko point or -, and the stones Black and White
+// have captured. For example "3/1X1/3 w - 0 0". ParsePosition reads it
+// back.
+func (g *Game) Position() string {
+	b := g.Board
+	var sb strings.Builder
+	for y := 0; y < b.Height; y++ {
+		if y > 0 {
+			sb.WriteByte('/')
+		}
+		empty := 0
+		for x := 0; x < b.Width; x++ {
+			if b.Grid[x][y] == Empty {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			sb.WriteByte(diagramGlyph(b.Grid[x][y]))
+		}
+		if empty > 0 {
+			sb.WriteString(strconv.Itoa(empty))
+		}
+	}
+
+	toMove := "b"
+	if g.CurrentTurn == White {
+		toMove = "w"
+	}
+	ko := "-"
+	if b.KoPoint != nil {
+		ko = b.Label(*b.KoPoint)
+	}
+	fmt.Fprintf(&sb, " %s %s %d %d", toMove, ko, b.Captures[Black], b.Captures[White])
+	return sb.String()
+}
+
+// ParsePosition starts a game from a position string as Position writes
+// it, with the default rules.
+func ParsePosition(s string) (*Game, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidPosition, len(fields))
+	}
+
+	var rows [][]Color
+	for _, text := range strings.Split(fields[0], "/") {
+		var row []Color
+		for i := 0; i < len(text); {
+			j := i
+			for j < len(text) && text[j] >= '0' && text[j] <= '9' {
+				j++
+			}
+			if j > i {
+				n, _ := strconv.Atoi(text[i:j])
+				if n == 0 || n > MaxBoardSize {
+					return nil, fmt.Errorf("%w: run of %q", ErrInvalidPosition, text[i:j])
+				}
+				row = append(row, make([]Color, n)...)
+				i = j
+				continue
+			}
+			switch text[i] {
+			case 'X':
+				row = append(row, Black)
+			case 'O':
+				row = append(row, White)
+			default:
+				return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidPosition, text[i])
+			}
+			i++
+		}
+		if len(rows) > 0 && len(row) != len(rows[0]) {
+			return nil, fmt.Errorf("%w: rows of %d and %d points", ErrInvalidPosition, len(rows[0]), len(row))
+		}
+		rows = append(rows, row)
+	}
+
+	g, err := NewRectangularGame(len(rows[0]), len(rows))
+	if err != nil {
+		return nil, err
+	}
+	for y, row := range rows {
+		for x, color := range row {
+			g.Board.SetStone(Point{x, y}, color)
+		}
+	}
+
+	switch fields[1] {
+	case "b":
+		g.CurrentTurn = Black
+	case "w":
+		g.CurrentTurn = White
+	default:
+		return nil, fmt.Errorf("%w: player to move %q", ErrInvalidPosition, fields[1])
+	}
+
+	if fields[2] != "-" {
+		p, err := g.Board.Size().ParseLabel(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: ko point: %v", ErrInvalidPosition, err)
+		}
+		if g.Board.Grid[p.X][p.Y] != Empty {
+			return nil, fmt.Errorf("%w: ko point %s is occupied", ErrInvalidPosition, fields[2])
+		}
+		ko := Point(p)
+		g.Board.KoPoint = &ko
+	}
+
+	for i, color := range []Color{Black, White} {
+		n, err := strconv.Atoi(fields[3+i])
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: captures %q", ErrInvalidPosition, fields[3+i])
+		}
+		g.Board.Captures[color] = n
+	}
+	return g, nil
+}
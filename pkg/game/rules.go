@@ -9,19 +9,57 @@ var (
 	ErrPositionOccupied  = errors.New("position already occupied")
 	ErrSuicideMove       = errors.New("suicide move not allowed")
 	ErrKoViolation       = errors.New("ko rule violation")
+	ErrSuperkoViolation  = errors.New("superko rule violation")
 	ErrGameOver          = errors.New("game is over")
 )
 
+// KoRule selects how repeated positions are restricted.
+type KoRule string
+
+const (
+	// NoKo allows any repetition.
+	NoKo KoRule = "none"
+	// SimpleKo forbids retaking a ko immediately.
+	SimpleKo KoRule = "simple"
+	// PositionalSuperko forbids recreating any earlier board position.
+	PositionalSuperko KoRule = "positional"
+	// SituationalSuperko forbids recreating an earlier board position
+	// with the same player to move.
+	SituationalSuperko KoRule = "situational"
+)
+
+// DefaultKomi is the komi of an even game under NewRules.
+const DefaultKomi = 6.5
+
 type Rules struct {
-	AllowSuicide bool
-	KoRule       bool
+	// Name is the preset the rules came from, or empty for custom rules.
+	Name                 string
+	AllowSuicide         bool
+	KoRule               KoRule
+	HandicapCompensation HandicapCompensation
+	Komi                 float64
+	ScoringMethod        ScoringMethod
+	PassStones           bool
+	EndCondition         EndCondition
 }
 
 func NewRules() *Rules {
 	return &Rules{
-		AllowSuicide: false,
-		KoRule:       true,
+		AllowSuicide:         false,
+		KoRule:               SimpleKo,
+		HandicapCompensation: ChineseCompensation,
+		Komi:                 DefaultKomi,
+		ScoringMethod:        JapaneseScoring,
+		EndCondition:         TwoPasses,
+	}
+}
+
+// SGFName returns the value of the SGF RU property for the rules.
+func (r *Rules) SGFName() string {
+	if rs, err := LookupRuleset(r.Name); err == nil {
+		return rs.SGFName
 	}
+	return sgfRulesName(r.ScoringMethod)
 }
 
 type Game struct {
@@ -32,11 +70,41 @@ type Game struct {
 	IsOver       bool
 	Winner       *Color
 	MoveCount    int
+	Handicap     int
+
+	// AgreeDeadStones makes the passes that end play start a scoring
+	// phase, in which the players agree on dead stones, instead of
+	// ending the game at once.
+	AgreeDeadStones bool
+	Scoring         bool
+	DeadStones      map[Point]bool
+	Accepted        map[Color]bool
+
+	// Tree holds every line played in the game, and Current is the node
+	// of the last move on the board, or Tree before the first.
+	Tree    *MoveNode
+	Current *MoveNode
 }
 
+// NewGame creates a game on an empty boardSize x boardSize board. It
+// panics if the size is invalid; NewRectangularGame validates instead.
 func NewGame(boardSize int) *Game {
+	return newGame(NewBoard(boardSize))
+}
+
+// NewRectangularGame creates a game on an empty board width points wide
+// and height points high, or returns ErrInvalidBoardSize.
+func NewRectangularGame(width, height int) (*Game, error) {
+	if err := ValidateBoardSize(width, height); err != nil {
+		return nil, err
+	}
+	return newGame(NewRectangularBoard(width, height)), nil
+}
+
+func newGame(board *Board) *Game {
+	tree := &MoveNode{}
 	return &Game{
-		Board:       NewBoard(boardSize),
+		Board:       board,
 		Rules:       NewRules(),
 		CurrentTurn: Black,
 		Passed: map[Color]bool{
@@ -45,14 +113,51 @@ func NewGame(boardSize int) *Game {
 		},
 		IsOver:    false,
 		MoveCount: 0,
+		Tree:      tree,
+		Current:   tree,
 	}
 }
 
+// Clone returns a copy of g, with the board's history but not the move
+// tree, that can be searched while g goes on changing.
+func (g *Game) Clone() *Game {
+	board := g.Board.Clone()
+	board.History = append([]BoardState(nil), g.Board.History...)
+	rules := *g.Rules
+
+	clone := &Game{
+		Board:           board,
+		Rules:           &rules,
+		CurrentTurn:     g.CurrentTurn,
+		Passed:          map[Color]bool{Black: g.Passed[Black], White: g.Passed[White]},
+		IsOver:          g.IsOver,
+		Winner:          g.Winner,
+		MoveCount:       g.MoveCount,
+		Handicap:        g.Handicap,
+		AgreeDeadStones: g.AgreeDeadStones,
+		Scoring:         g.Scoring,
+	}
+	if g.DeadStones != nil {
+		clone.DeadStones = make(map[Point]bool, len(g.DeadStones))
+		for p, dead := range g.DeadStones {
+			clone.DeadStones[p] = dead
+		}
+	}
+	if g.Accepted != nil {
+		clone.Accepted = map[Color]bool{Black: g.Accepted[Black], White: g.Accepted[White]}
+	}
+	return clone
+}
+
 func (g *Game) ValidateMove(p Point, color Color) error {
 	if g.IsOver {
 		return ErrGameOver
 	}
 
+	if g.Scoring {
+		return ErrScoringPhase
+	}
+
 	if color != g.CurrentTurn {
 		return errors.New("not your turn")
 	}
@@ -65,25 +170,33 @@ func (g *Game) ValidateMove(p Point, color Color) error {
 		return ErrPositionOccupied
 	}
 
-	tempBoard := g.Board.Clone()
-	tempBoard.SetStone(p, color)
+	if !g.Rules.AllowSuicide && g.Board.IsSuicide(p, color) {
+		return ErrSuicideMove
+	}
 
-	capturedOpponent := tempBoard.CaptureDeadGroups(color)
+	return g.checkKo(p, color)
+}
 
-	if capturedOpponent == 0 && !tempBoard.HasLiberties(p) {
-		if !g.Rules.AllowSuicide {
-			return ErrSuicideMove
-		}
-		selfGroup := tempBoard.GetGroup(p)
-		if len(selfGroup) > 0 && !tempBoard.HasLiberties(selfGroup[0]) {
-			return ErrSuicideMove
-		}
+func (g *Game) checkKo(p Point, color Color) error {
+	if g.Rules.KoRule == NoKo || g.Rules.KoRule == "" {
+		return nil
 	}
 
-	if g.Rules.KoRule && g.Board.IsKo(p, color) {
+	if g.Board.IsKo(p, color) {
 		return ErrKoViolation
 	}
 
+	switch g.Rules.KoRule {
+	case PositionalSuperko:
+		if g.Board.HasSeenPosition(g.Board.HashAfterMove(p, color)) {
+			return ErrSuperkoViolation
+		}
+	case SituationalSuperko:
+		if g.Board.HasSeenSituation(g.Board.HashAfterMove(p, color), OpponentColor(color)) {
+			return ErrSuperkoViolation
+		}
+	}
+
 	return nil
 }
 
@@ -92,12 +205,18 @@ func (g *Game) MakeMove(p Point, color Color) error {
 		return err
 	}
 
+	g.advance(&p, color, nil)
 	g.Board.SaveState(&p, color)
 
-	g.Board.SetStone(p, color)
-
-	captured := g.Board.CaptureDeadGroups(color)
-	g.Board.Captures[color] += captured
+	suicide := g.Board.IsSuicide(p, color)
+	captured := g.Board.Play(p, color)
+	g.Board.Captures[color] += len(captured)
+	var lost []Point
+	if suicide {
+		lost = g.Board.GetGroup(p)
+		g.Board.Captures[OpponentColor(color)] += g.Board.RemoveGroup(lost)
+	}
+	g.Board.recordCaptures(captured, lost)
 
 	g.Board.LastMove = &p
 	g.CurrentTurn = OpponentColor(color)
@@ -106,29 +225,8 @@ func (g *Game) MakeMove(p Point, color Color) error {
 	g.Passed[color] = false
 
 	g.Board.KoPoint = nil
-	if captured == 1 {
-		capturedGroup := []Point{}
-		for x := 0; x < g.Board.Size; x++ {
-			for y := 0; y < g.Board.Size; y++ {
-				point := Point{x, y}
-				if g.Board.GetColor(point) == Empty {
-					neighbors := g.Board.GetNeighbors(point)
-					surroundedByOpponent := true
-					for _, n := range neighbors {
-						if g.Board.GetColor(n) != OpponentColor(color) {
-							surroundedByOpponent = false
-							break
-						}
-					}
-					if surroundedByOpponent && len(neighbors) == len(g.Board.GetNeighbors(point)) {
-						capturedGroup = append(capturedGroup, point)
-					}
-				}
-			}
-		}
-		if len(capturedGroup) == 1 {
-			g.Board.KoPoint = &capturedGroup[0]
-		}
+	if !suicide && len(captured) == 1 && len(g.Board.GetGroup(p)) == 1 && g.Board.LibertyCount(p) == 1 {
+		g.Board.KoPoint = &captured[0]
 	}
 
 	return nil
@@ -139,54 +237,92 @@ func (g *Game) Pass(color Color) error {
 		return ErrGameOver
 	}
 
+	if g.Scoring {
+		return ErrScoringPhase
+	}
+
 	if color != g.CurrentTurn {
 		return errors.New("not your turn")
 	}
 
+	g.advance(nil, color, nil)
+	g.Board.SaveState(nil, color)
+
 	g.Passed[color] = true
 	g.CurrentTurn = OpponentColor(color)
+	g.Board.KoPoint = nil
+	if g.Rules.PassStones {
+		g.Board.Captures[OpponentColor(color)]++
+	}
 
-	g.Board.SaveState(nil, color)
-
-	if g.Passed[Black] && g.Passed[White] {
-		g.EndGame()
+	if g.Passed[Black] && g.Passed[White] && (g.Rules.EndCondition != WhitePassesLast || color == White) {
+		if g.AgreeDeadStones && g.Rules.ScoringMethod != TrompTaylorScoring {
+			g.startScoring()
+		} else {
+			g.EndGame()
+		}
 	}
 
 	return nil
 }
 
+// SetUp changes stones outside play, as SGF AB, AW and AE properties do,
+// setting each point in stones to its color. The change is a node in the
+// tree, so Undo takes it back and records written from the game keep it.
+// The same player stays on move.
+func (g *Game) SetUp(stones map[Point]Color) {
+	g.advance(nil, g.CurrentTurn, stones)
+	g.Board.SetUp(stones, g.CurrentTurn)
+}
+
 func (g *Game) EndGame() {
 	g.IsOver = true
-	scores := g.CalculateScore()
-	
-	if scores[Black] > scores[White] {
-		winner := Black
-		g.Winner = &winner
-	} else if scores[White] > scores[Black] {
-		winner := White
-		g.Winner = &winner
-	}
+	g.Winner = g.Score().Winner
 }
 
-func (g *Game) CalculateScore() map[Color]int {
-	territory := g.Board.CountTerritory()
-	
-	scores := map[Color]int{
-		Black: territory[Black] + g.Board.Captures[Black],
-		White: territory[White] + g.Board.Captures[White],
+// Score counts the position with the game's scoring method, komi and
+// handicap compensation. Only stones marked in DeadStones are dead.
+func (g *Game) Score() *Score {
+	board := g.Board
+	if len(g.DeadStones) > 0 {
+		board = withoutDeadStones(board, g.DeadStones)
+	}
+	score := CalculateScore(board, g.Rules.ScoringMethod, g.Rules.Komi)
+	if compensation := g.compensation(); compensation != 0 {
+		score.Compensation = compensation
+		score.White += compensation
+		score.determineWinner()
 	}
+	return score
+}
+
+// compensation is what White receives for handicap stones, which only
+// area scoring gives.
+func (g *Game) compensation() float64 {
+	return g.compensationUnder(g.Rules.ScoringMethod)
+}
 
-	komi := 6.5
-	scores[White] = int(float64(scores[White]) + komi)
+// compensationUnder is compensation for a game scored with method.
+func (g *Game) compensationUnder(method ScoringMethod) float64 {
+	if method == JapaneseScoring || g.Rules.HandicapCompensation == "" {
+		return 0
+	}
+	return g.Rules.HandicapCompensation.Points(g.Handicap)
+}
 
-	return scores
+func (g *Game) CalculateScore() map[Color]float64 {
+	score := g.Score()
+	return map[Color]float64{
+		Black: score.Black,
+		White: score.White,
+	}
 }
 
 func (g *Game) GetValidMoves(color Color) []Point {
 	validMoves := []Point{}
 	
-	for x := 0; x < g.Board.Size; x++ {
-		for y := 0; y < g.Board.Size; y++ {
+	for x := 0; x < g.Board.Width; x++ {
+		for y := 0; y < g.Board.Height; y++ {
 			p := Point{x, y}
 			if g.ValidateMove(p, color) == nil {
 				validMoves = append(validMoves, p)
@@ -199,66 +335,43 @@ func (g *Game) GetValidMoves(color Color) []Point {
 
 func (g *Game) Resign(color Color) {
 	g.IsOver = true
+	g.Scoring = false
 	winner := OpponentColor(color)
 	g.Winner = &winner
 }
 
 func (g *Game) GetGameInfo() map[string]interface{} {
 	info := map[string]interface{}{
-		"boardSize":    g.Board.Size,
+		"boardSize":    g.Board.Width,
+		"boardWidth":   g.Board.Width,
+		"boardHeight":  g.Board.Height,
 		"currentTurn":  g.CurrentTurn.String(),
 		"moveCount":    g.MoveCount,
 		"isOver":       g.IsOver,
 		"blackCaptures": g.Board.Captures[Black],
 		"whiteCaptures": g.Board.Captures[White],
+		"handicap":      g.Handicap,
+		"komi":          g.Rules.Komi,
+		"rules":         g.Rules.ScoringMethod,
+		"scoring":       g.Scoring,
+	}
+	if g.Rules.Name != "" {
+		info["ruleset"] = g.Rules.Name
 	}
 
 	if g.IsOver && g.Winner != nil {
 		info["winner"] = g.Winner.String()
-		info["scores"] = g.CalculateScore()
+		info["scores"] = g.Score().Summary()
 	}
 
 	return info
 }
 
 func (g *Game) GetBoardState() [][]Color {
-	state := make([][]Color, g.Board.Size)
+	state := make([][]Color, g.Board.Width)
 	for i := range state {
-		state[i] = make([]Color, g.Board.Size)
+		state[i] = make([]Color, g.Board.Height)
 		copy(state[i], g.Board.Grid[i])
 	}
 	return state
 }
-
-func (g *Game) Undo() bool {
-	if len(g.Board.History) <= 1 {
-		return false
-	}
-
-	g.Board.History = g.Board.History[:len(g.Board.History)-1]
-	
-	if len(g.Board.History) > 0 {
-		lastState := g.Board.History[len(g.Board.History)-1]
-		
-		for i := range g.Board.Grid {
-			copy(g.Board.Grid[i], lastState.Grid[i])
-		}
-		
-		for k, v := range lastState.Captures {
-			g.Board.Captures[k] = v
-		}
-		
-		g.Board.LastMove = lastState.Move
-		g.CurrentTurn = OpponentColor(lastState.Player)
-		g.MoveCount--
-		
-		g.Passed[Black] = false
-		g.Passed[White] = false
-		g.IsOver = false
-		g.Winner = nil
-		
-		return true
-	}
-	
-	return false
-}
\ No newline at end of file
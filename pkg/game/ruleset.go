@@ -0,0 +1,159 @@
+package game
+
+import "strings"
+
+// EndCondition says which passes end the game.
+type EndCondition string
+
+const (
+	// TwoPasses ends the game after two consecutive passes.
+	TwoPasses EndCondition = "two_passes"
+	// WhitePassesLast ends the game after two consecutive passes of
+	// which White's is the second, as in AGA rules.
+	WhitePassesLast EndCondition = "white_passes_last"
+)
+
+// Ruleset is a named set of rules as published by a Go association.
+type Ruleset struct {
+	Name                 string
+	KoRule               KoRule
+	AllowSuicide         bool
+	ScoringMethod        ScoringMethod
+	Komi                 float64
+	HandicapCompensation HandicapCompensation
+	// PassStones makes a player hand the opponent a prisoner for each
+	// pass, so that territory and area counts agree. Only territory
+	// scoring counts prisoners, so area-scored presets leave it off.
+	PassStones   bool
+	EndCondition EndCondition
+	// SGFName is the value of the SGF RU property.
+	SGFName string
+	// GTPName is the argument of the GTP kgs-rules command.
+	GTPName string
+}
+
+var rulesets = []Ruleset{
+	{
+		Name:                 "japanese",
+		KoRule:               SimpleKo,
+		ScoringMethod:        JapaneseScoring,
+		Komi:                 6.5,
+		HandicapCompensation: NoCompensation,
+		EndCondition:         TwoPasses,
+		SGFName:              "Japanese",
+		GTPName:              "japanese",
+	},
+	{
+		Name:                 "chinese",
+		KoRule:               PositionalSuperko,
+		ScoringMethod:        ChineseScoring,
+		Komi:                 7.5,
+		HandicapCompensation: ChineseCompensation,
+		EndCondition:         TwoPasses,
+		SGFName:              "Chinese",
+		GTPName:              "chinese",
+	},
+	{
+		Name:                 "aga",
+		KoRule:               SituationalSuperko,
+		ScoringMethod:        ChineseScoring,
+		Komi:                 7.5,
+		HandicapCompensation: AGACompensation,
+		EndCondition:         WhitePassesLast,
+		SGFName:              "AGA",
+		GTPName:              "aga",
+	},
+	{
+		Name:                 "new_zealand",
+		KoRule:               SituationalSuperko,
+		AllowSuicide:         true,
+		ScoringMethod:        ChineseScoring,
+		Komi:                 7,
+		HandicapCompensation: NoCompensation,
+		EndCondition:         TwoPasses,
+		SGFName:              "NZ",
+		GTPName:              "new_zealand",
+	},
+	{
+		Name:                 "tromp-taylor",
+		KoRule:               PositionalSuperko,
+		AllowSuicide:         true,
+		ScoringMethod:        TrompTaylorScoring,
+		Komi:                 7.5,
+		HandicapCompensation: NoCompensation,
+		EndCondition:         TwoPasses,
+		SGFName:              "Tromp-Taylor",
+		GTPName:              "tromp-taylor",
+	},
+	{
+		Name:                 "ing",
+		KoRule:               SituationalSuperko,
+		AllowSuicide:         true,
+		ScoringMethod:        ChineseScoring,
+		Komi:                 7.5,
+		HandicapCompensation: ChineseCompensation,
+		EndCondition:         TwoPasses,
+		SGFName:              "GOE",
+		GTPName:              "ing",
+	},
+}
+
+// Rulesets returns the preset rulesets.
+func Rulesets() []Ruleset {
+	presets := make([]Ruleset, len(rulesets))
+	copy(presets, rulesets)
+	return presets
+}
+
+// LookupRuleset returns the preset with the given name, SGF name or GTP
+// name. Case, spaces, hyphens and underscores are ignored.
+func LookupRuleset(name string) (Ruleset, error) {
+	key := rulesetKey(name)
+	for _, rs := range rulesets {
+		if key == rulesetKey(rs.Name) || key == rulesetKey(rs.SGFName) || key == rulesetKey(rs.GTPName) {
+			return rs, nil
+		}
+	}
+	return Ruleset{}, ErrUnknownRules
+}
+
+func rulesetKey(name string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+// Rules returns a new Rules set to the preset.
+func (rs Ruleset) Rules() *Rules {
+	return &Rules{
+		Name:                 rs.Name,
+		AllowSuicide:         rs.AllowSuicide,
+		KoRule:               rs.KoRule,
+		HandicapCompensation: rs.HandicapCompensation,
+		Komi:                 rs.Komi,
+		ScoringMethod:        rs.ScoringMethod,
+		PassStones:           rs.PassStones,
+		EndCondition:         rs.EndCondition,
+	}
+}
+
+// NewGameWithRuleset creates a game played under the named preset, or
+// returns ErrInvalidBoardSize.
+func NewGameWithRuleset(boardSize int, name string) (*Game, error) {
+	g, err := NewRectangularGame(boardSize, boardSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.SetRuleset(name); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SetRuleset replaces the game's rules with the named preset.
+func (g *Game) SetRuleset(name string) error {
+	rs, err := LookupRuleset(name)
+	if err != nil {
+		return err
+	}
+	g.Rules = rs.Rules()
+	return nil
+}
@@ -1,6 +1,13 @@
 package game
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrUnknownRules = errors.New("unknown rules")
 
 type ScoringMethod string
 
@@ -10,17 +17,24 @@ const (
 )
 
 type Score struct {
-	Black      float64
-	White      float64
-	Territory  map[Color]int
-	Captures   map[Color]int
-	Komi       float64
-	Method     ScoringMethod
-	Winner     *Color
-	Difference float64
+	Black     float64
+	White     float64
+	Territory map[Color]int
+	Captures  map[Color]int
+	Komi      float64
+	// Compensation is what White receives for Black's handicap stones
+	// under area scoring.
+	Compensation float64
+	Method       ScoringMethod
+	Winner       *Color
+	Difference   float64
 }
 
 func CalculateScore(board *Board, method ScoringMethod, komi float64) *Score {
+	if method == TrompTaylorScoring {
+		return TrompTaylorScore(board, komi)
+	}
+
 	score := &Score{
 		Territory: board.CountTerritory(),
 		Captures:  board.Captures,
@@ -32,6 +46,12 @@ func CalculateScore(board *Board, method ScoringMethod, komi float64) *Score {
 	case ChineseScoring:
 		score.calculateChineseScore(board)
 	case JapaneseScoring:
+		// Points enclosed by stones in seki are not territory.
+		for _, seki := range FindSeki(board) {
+			for _, owner := range seki.Eyes {
+				score.Territory[owner]--
+			}
+		}
 		score.calculateJapaneseScore(board)
 	default:
 		score.calculateChineseScore(board)
@@ -45,8 +65,8 @@ func (s *Score) calculateChineseScore(board *Board) {
 	blackStones := 0
 	whiteStones := 0
 
-	for x := 0; x < board.Size; x++ {
-		for y := 0; y < board.Size; y++ {
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
 			color := board.Grid[x][y]
 			if color == Black {
 				blackStones++
@@ -95,6 +115,24 @@ func (s *Score) GetResult() string {
 	return winnerStr + "+" + formatFloat(diff)
 }
 
+// Summary returns the totals and result in the form sent to clients.
+func (s *Score) Summary() map[string]interface{} {
+	return map[string]interface{}{
+		"Black":  s.Black,
+		"White":  s.White,
+		"result": s.GetResult(),
+	}
+}
+
+// ParseScoringMethod returns the scoring method with the given name.
+func ParseScoringMethod(name string) (ScoringMethod, error) {
+	switch method := ScoringMethod(strings.ToLower(name)); method {
+	case ChineseScoring, JapaneseScoring, TrompTaylorScoring:
+		return method, nil
+	}
+	return "", ErrUnknownRules
+}
+
 func formatFloat(f float64) string {
 	if f == float64(int(f)) {
 		return fmt.Sprintf("%d", int(f))
@@ -114,13 +152,22 @@ func MarkDeadStones(board *Board) *DeadStoneMarker {
 	}
 
 	visited := make(map[Point]bool)
-
-	for x := 0; x < board.Size; x++ {
-		for y := 0; y < board.Size; y++ {
+	seki := sekiStones(board)
+	// Benson's algorithm settles some groups for certain: pass-alive
+	// chains live, and stones inside pass-alive territory are dead.
+	alive := passAliveStones(board)
+	territory := PassAliveTerritory(board)
+
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
 			p := Point{x, y}
 			if !visited[p] && board.GetColor(p) != Empty {
 				group := board.GetGroup(p)
-				if isGroupDead(board, group) {
+				dead := !seki[p] && !alive[p] && isGroupDead(board, group)
+				if owner, ok := territory[p]; ok && owner != board.GetColor(p) {
+					dead = true
+				}
+				if dead {
 					marker.Groups = append(marker.Groups, group)
 					for _, stone := range group {
 						marker.Stones[stone] = true
@@ -201,8 +248,8 @@ func EstimateTerritory(board *Board) map[Point]Color {
 	territory := make(map[Point]Color)
 	visited := make(map[Point]bool)
 
-	for x := 0; x < board.Size; x++ {
-		for y := 0; y < board.Size; y++ {
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
 			p := Point{x, y}
 			if board.GetColor(p) == Empty && !visited[p] {
 				region, owner := findTerritoryRegion(board, p, visited)
@@ -267,45 +314,62 @@ type GameResult struct {
 }
 
 func GetGameResult(game *Game, method ScoringMethod, komi float64) *GameResult {
-	deadStones := MarkDeadStones(game.Board)
-	
-	boardCopy := game.Board.Clone()
-	for stone := range deadStones.Stones {
-		boardCopy.SetStone(stone, Empty)
+	// Stones the players marked take precedence over the heuristic, and
+	// Tromp-Taylor scoring removes none.
+	var deadStones *DeadStoneMarker
+	switch {
+	case method == TrompTaylorScoring:
+		deadStones = newDeadStoneMarker(game.Board, nil)
+	case game.DeadStones != nil:
+		deadStones = newDeadStoneMarker(game.Board, game.DeadStones)
+	default:
+		deadStones = MarkDeadStones(game.Board)
 	}
+
+	boardCopy := withoutDeadStones(game.Board, deadStones.Stones)
 	
 	territory := EstimateTerritory(boardCopy)
+	if method == JapaneseScoring {
+		for _, seki := range FindSeki(boardCopy) {
+			for p := range seki.Eyes {
+				delete(territory, p)
+			}
+		}
+	}
 	score := CalculateScore(boardCopy, method, komi)
+	if compensation := game.compensationUnder(method); compensation != 0 {
+		score.Compensation = compensation
+		score.White += compensation
+		score.determineWinner()
+	}
 	
 	return &GameResult{
 		Score:      score,
 		DeadStones: deadStones,
 		Territory:  territory,
-		SGF:        generateSGF(game),
+		SGF:        generateSGF(game, score, territory),
 	}
 }
 
-func generateSGF(game *Game) string {
-	sgf := fmt.Sprintf("(;FF[4]GM[1]SZ[%d]", game.Board.Size)
-	
-	for i, state := range game.Board.History {
-		if state.Move != nil {
-			color := "B"
-			if state.Player == White {
-				color = "W"
-			}
-			x := string(rune('a' + state.Move.X))
-			y := string(rune('a' + state.Move.Y))
-			sgf += ";" + color + "[" + x + y + "]"
-		} else if i > 0 {
-			color := "B"
-			if state.Player == White {
-				color = "W"
-			}
-			sgf += ";" + color + "[]"
-		}
+func generateSGF(game *Game, score *Score, territory map[Point]Color) string {
+	record := NewRecordFromGame(game)
+	record.Komi = score.Komi
+	if score.Method != game.Rules.ScoringMethod {
+		record.Rules = sgfRulesName(score.Method)
 	}
-	
-	sgf += ")"
-	return sgf
-}
\ No newline at end of file
+	record.Result = score.GetResult()
+	record.Date = time.Now().Format("2006-01-02")
+	record.MarkTerritory(record.LastNode(), territory)
+
+	return record.SGF()
+}
+
+func sgfRulesName(method ScoringMethod) string {
+	switch method {
+	case JapaneseScoring:
+		return "Japanese"
+	case TrompTaylorScoring:
+		return "Tromp-Taylor"
+	}
+	return "Chinese"
+}
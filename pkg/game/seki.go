@@ -0,0 +1,257 @@
+package game
+
+import "sort"
+
+// Seki is a set of chains of both colors that live only because neither
+// side can fill their shared liberties without being captured.
+type Seki struct {
+	Stones []Point
+	// Shared are the liberties the chains share, which neither side can
+	// fill.
+	Shared []Point
+	// Eyes are the empty points enclosed by the seki chains alone, with
+	// the color that encloses them. Territory scoring counts them as dame.
+	Eyes map[Point]Color
+}
+
+// emptyRegion is a maximal connected set of empty points.
+type emptyRegion struct {
+	points  []Point
+	owner   Color
+	borders []*chain
+}
+
+// FindSeki returns the seki on the board. A chain is in seki when it has
+// fewer than two eyes and all its other liberties are shared points that
+// would be self-atari for either color to fill.
+func FindSeki(board *Board) []Seki {
+	regions := board.emptyRegions()
+
+	shared := make(map[Point]bool)
+	for _, r := range regions {
+		if r.owner != Empty {
+			continue
+		}
+		for _, p := range r.points {
+			if board.touchesBoth(p) && board.isSelfAtari(p, Black) && board.isSelfAtari(p, White) {
+				shared[p] = true
+			}
+		}
+	}
+	if len(shared) == 0 {
+		return nil
+	}
+
+	regionAt := make(map[Point]*emptyRegion)
+	for _, r := range regions {
+		for _, p := range r.points {
+			regionAt[p] = r
+		}
+	}
+
+	// Chains whose liberties allow seki, found through the shared points.
+	candidates := make(map[*chain]bool)
+	for p := range shared {
+		for _, n := range board.GetNeighbors(p) {
+			c := board.chainAt(n)
+			if c == nil {
+				continue
+			}
+			if _, seen := candidates[c]; !seen {
+				candidates[c] = board.canBeSeki(c, shared, regionAt)
+			}
+		}
+	}
+
+	// Join chains that share a liberty; a component is a seki only if
+	// every chain on its shared points qualifies.
+	parent := make(map[*chain]*chain)
+	var find func(c *chain) *chain
+	find = func(c *chain) *chain {
+		if parent[c] == nil || parent[c] == c {
+			return c
+		}
+		parent[c] = find(parent[c])
+		return parent[c]
+	}
+	for p := range shared {
+		var first *chain
+		for _, n := range board.GetNeighbors(p) {
+			if c := board.chainAt(n); c != nil {
+				if first == nil {
+					first = c
+				} else if root, other := find(first), find(c); root != other {
+					parent[other] = root
+				}
+			}
+		}
+	}
+
+	valid := make(map[*chain]bool)
+	for c, ok := range candidates {
+		root := find(c)
+		if _, seen := valid[root]; !seen {
+			valid[root] = true
+		}
+		valid[root] = valid[root] && ok
+	}
+
+	components := make(map[*chain]*Seki)
+	var roots []*chain
+	component := func(c *chain) *Seki {
+		root := find(c)
+		if !valid[root] {
+			return nil
+		}
+		s, ok := components[root]
+		if !ok {
+			s = &Seki{Eyes: make(map[Point]Color)}
+			components[root] = s
+			roots = append(roots, root)
+		}
+		return s
+	}
+
+	for c := range candidates {
+		if s := component(c); s != nil {
+			s.Stones = append(s.Stones, c.stones...)
+		}
+	}
+	for p := range shared {
+		for _, n := range board.GetNeighbors(p) {
+			if c := board.chainAt(n); c != nil {
+				if s := component(c); s != nil {
+					s.Shared = append(s.Shared, p)
+				}
+				break
+			}
+		}
+	}
+	for _, r := range regions {
+		if r.owner == Empty || len(r.borders) == 0 {
+			continue
+		}
+		s := component(r.borders[0])
+		if s == nil {
+			continue
+		}
+		enclosed := true
+		for _, c := range r.borders {
+			if !candidates[c] || component(c) != s {
+				enclosed = false
+				break
+			}
+		}
+		if enclosed {
+			for _, p := range r.points {
+				s.Eyes[p] = r.owner
+			}
+		}
+	}
+
+	sekis := make([]Seki, 0, len(roots))
+	for _, root := range roots {
+		s := components[root]
+		sortPoints(s.Stones)
+		sortPoints(s.Shared)
+		sekis = append(sekis, *s)
+	}
+	sort.Slice(sekis, func(i, j int) bool {
+		a, b := sekis[i].Stones[0], sekis[j].Stones[0]
+		return a.Y < b.Y || a.Y == b.Y && a.X < b.X
+	})
+	return sekis
+}
+
+// sekiStones returns the stones of every seki on the board.
+func sekiStones(board *Board) map[Point]bool {
+	stones := make(map[Point]bool)
+	for _, s := range FindSeki(board) {
+		for _, p := range s.Stones {
+			stones[p] = true
+		}
+	}
+	return stones
+}
+
+// canBeSeki reports whether every liberty of c is either a shared point
+// or lies in one of fewer than two eyes of c's color.
+func (b *Board) canBeSeki(c *chain, shared map[Point]bool, regionAt map[Point]*emptyRegion) bool {
+	eyes := make(map[*emptyRegion]bool)
+	for l := range c.liberties {
+		if shared[l] {
+			continue
+		}
+		r := regionAt[l]
+		if r == nil || r.owner != c.color {
+			return false
+		}
+		eyes[r] = true
+	}
+	return len(eyes) < 2
+}
+
+// isSelfAtari reports whether color playing at p would capture nothing
+// and leave its chain with at most one liberty.
+func (b *Board) isSelfAtari(p Point, color Color) bool {
+	liberties := make(map[Point]struct{})
+	for _, n := range b.GetNeighbors(p) {
+		c := b.chainAt(n)
+		switch {
+		case c == nil:
+			liberties[n] = struct{}{}
+		case c.color == color:
+			for l := range c.liberties {
+				if l != p {
+					liberties[l] = struct{}{}
+				}
+			}
+		case len(c.liberties) == 1:
+			return false
+		}
+	}
+	return len(liberties) <= 1
+}
+
+func (b *Board) touchesBoth(p Point) bool {
+	black, white := false, false
+	for _, n := range b.GetNeighbors(p) {
+		switch b.GetColor(n) {
+		case Black:
+			black = true
+		case White:
+			white = true
+		}
+	}
+	return black && white
+}
+
+// emptyRegions returns the board's empty regions with their owners and
+// the chains around them.
+func (b *Board) emptyRegions() []*emptyRegion {
+	visited := make(map[Point]bool)
+	regions := []*emptyRegion{}
+
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
+			p := Point{x, y}
+			if visited[p] || b.GetColor(p) != Empty {
+				continue
+			}
+
+			points, owner := b.findTerritoryRegion(p, visited)
+			r := &emptyRegion{points: points, owner: owner}
+			seen := make(map[*chain]bool)
+			for _, q := range points {
+				for _, n := range b.GetNeighbors(q) {
+					if c := b.chainAt(n); c != nil && !seen[c] {
+						seen[c] = true
+						r.borders = append(r.borders, c)
+					}
+				}
+			}
+			regions = append(regions, r)
+		}
+	}
+	return regions
+}
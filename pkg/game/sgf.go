@@ -0,0 +1,540 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/coord"
+	"github.com/Prawal-Sharma/GoSim/pkg/sgf"
+)
+
+var (
+	ErrNotGo = errors.New("sgf: not a Go game")
+)
+
+// Record is one game from an SGF collection: its root metadata and the
+// full node tree, which may contain variations.
+type Record struct {
+	Root        *sgf.Node
+	Width       int
+	Height      int
+	Komi        float64
+	Handicap    int
+	Rules       string
+	BlackPlayer string
+	WhitePlayer string
+	BlackRank   string
+	WhiteRank   string
+	Result      string
+	Date        string
+	Event       string
+	GameName    string
+	TimeLimit   float64
+	Overtime    string
+}
+
+// LoadSGF reads every game in an SGF collection.
+func LoadSGF(r io.Reader) ([]*Record, error) {
+	collection, err := sgf.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*Record, 0, len(collection))
+	for i, root := range collection {
+		record, err := NewRecord(root)
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// NewRecord reads the root properties of an SGF game tree.
+func NewRecord(root *sgf.Node) (*Record, error) {
+	if gm := root.Get("GM"); gm != "" && gm != "1" {
+		return nil, ErrNotGo
+	}
+
+	record := &Record{
+		Root:        root,
+		Width:       19,
+		Height:      19,
+		Rules:       root.Get("RU"),
+		BlackPlayer: root.Get("PB"),
+		WhitePlayer: root.Get("PW"),
+		BlackRank:   root.Get("BR"),
+		WhiteRank:   root.Get("WR"),
+		Result:      root.Get("RE"),
+		Date:        root.Get("DT"),
+		Event:       root.Get("EV"),
+		GameName:    root.Get("GN"),
+		Overtime:    root.Get("OT"),
+	}
+
+	if sz := root.Get("SZ"); sz != "" {
+		width, height, err := parseSGFSize(sz)
+		if err != nil {
+			return nil, err
+		}
+		record.Width, record.Height = width, height
+	}
+
+	if km := root.Get("KM"); km != "" {
+		komi, err := strconv.ParseFloat(km, 64)
+		if err != nil {
+			return nil, fmt.Errorf("sgf: invalid KM[%s]", km)
+		}
+		record.Komi = komi
+	}
+
+	if tm := root.Get("TM"); tm != "" {
+		timeLimit, err := strconv.ParseFloat(tm, 64)
+		if err != nil {
+			return nil, fmt.Errorf("sgf: invalid TM[%s]", tm)
+		}
+		record.TimeLimit = timeLimit
+	}
+
+	if ha := root.Get("HA"); ha != "" {
+		handicap, err := strconv.Atoi(ha)
+		if err != nil {
+			return nil, fmt.Errorf("sgf: invalid HA[%s]", ha)
+		}
+		record.Handicap = handicap
+	}
+
+	return record, nil
+}
+
+func parseSGFSize(value string) (int, int, error) {
+	first, second, composed := sgf.SplitCompose(value)
+
+	width, err := strconv.Atoi(first)
+	if err != nil || width < 1 || width > MaxBoardSize {
+		return 0, 0, fmt.Errorf("sgf: invalid SZ[%s]", value)
+	}
+	if !composed {
+		return width, width, nil
+	}
+
+	height, err := strconv.Atoi(second)
+	if err != nil || height < 1 || height > MaxBoardSize {
+		return 0, 0, fmt.Errorf("sgf: invalid SZ[%s]", value)
+	}
+	return width, height, nil
+}
+
+// MainLine returns the nodes of the main line from the root to its end.
+func (r *Record) MainLine() []*sgf.Node {
+	return r.Root.MainLine()
+}
+
+// Game replays the main line to its last node.
+func (r *Record) Game() (*Game, error) {
+	line := r.MainLine()
+	return r.GameAt(line[len(line)-1])
+}
+
+// GameAt replays the record from the root to node, which may lie in any
+// variation, and returns the resulting game.
+func (r *Record) GameAt(node *sgf.Node) (*Game, error) {
+	if node.Root() != r.Root {
+		return nil, errors.New("sgf: node does not belong to this record")
+	}
+	g, err := NewRectangularGame(r.Width, r.Height)
+	if err != nil {
+		return nil, err
+	}
+	if rs, err := LookupRuleset(r.Rules); err == nil {
+		g.Rules = rs.Rules()
+	}
+	for depth, n := range node.Path() {
+		if err := g.applySGFNode(n, r.Width, r.Height); err != nil {
+			return nil, fmt.Errorf("node %d: %w", depth, err)
+		}
+	}
+	if r.Handicap >= 2 {
+		g.Handicap = r.Handicap
+	}
+	if r.Root.Has("KM") {
+		g.Rules.Komi = r.Komi
+	}
+	return g, nil
+}
+
+func (g *Game) applySGFNode(node *sgf.Node, width, height int) error {
+	setup := []struct {
+		id    string
+		color Color
+	}{
+		{"AE", Empty},
+		{"AB", Black},
+		{"AW", White},
+	}
+	stones := make(map[Point]Color)
+	for _, s := range setup {
+		points, err := parseSGFPointList(node.Values(s.id), width, height)
+		if err != nil {
+			return err
+		}
+		for _, p := range points {
+			stones[p] = s.color
+		}
+	}
+	// Setup before any move is part of the starting position; later it
+	// is a node of its own, so that undo and the writer see it.
+	if len(g.Board.History) == 0 {
+		for p, color := range stones {
+			g.Board.SetStone(p, color)
+		}
+	} else if len(stones) > 0 {
+		if g.IsOver {
+			g.IsOver = false
+			g.Winner = nil
+		}
+		g.SetUp(stones)
+	}
+
+	if pl := node.Get("PL"); pl != "" {
+		color, err := parseSGFColor(pl)
+		if err != nil {
+			return err
+		}
+		g.CurrentTurn = color
+	}
+
+	for _, id := range []string{"B", "W"} {
+		if !node.Has(id) {
+			continue
+		}
+		color, _ := parseSGFColor(id)
+		if err := g.playSGFMove(node.Get(id), color, width, height); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// playSGFMove plays a recorded move regardless of whose turn the game
+// thinks it is, since records may contain consecutive moves by one color
+// or continue after both players passed.
+func (g *Game) playSGFMove(value string, color Color, width, height int) error {
+	if g.IsOver {
+		g.IsOver = false
+		g.Winner = nil
+	}
+	g.CurrentTurn = color
+
+	p, pass, err := parseSGFPoint(value, width, height)
+	if err != nil {
+		return err
+	}
+	if pass {
+		return g.Pass(color)
+	}
+	if err := g.MakeMove(p, color); err != nil {
+		return fmt.Errorf("%s[%s]: %w", color.String(), value, err)
+	}
+	return nil
+}
+
+func parseSGFColor(value string) (Color, error) {
+	switch value {
+	case "B", "b":
+		return Black, nil
+	case "W", "w":
+		return White, nil
+	}
+	return Empty, fmt.Errorf("sgf: invalid color %q", value)
+}
+
+// parseSGFPoint decodes a move value such as "dd". An empty value, or
+// "tt" on boards up to 19x19, is a pass.
+func parseSGFPoint(value string, width, height int) (Point, bool, error) {
+	p, pass, err := coord.Size{Width: width, Height: height}.ParseSGF(value)
+	if err != nil {
+		return Point{}, false, fmt.Errorf("sgf: %w", err)
+	}
+	return Point(p), pass, nil
+}
+
+// sgfPoint encodes p as an SGF point such as "dd".
+func sgfPoint(p Point) string {
+	return coord.SGF(coord.Point(p))
+}
+
+// parseSGFPointList decodes a list of points, expanding compressed
+// rectangles such as "aa:cc".
+func parseSGFPointList(values []string, width, height int) ([]Point, error) {
+	points := []Point{}
+	for _, value := range values {
+		first, second, composed := sgf.SplitCompose(value)
+
+		from, pass, err := parseSGFPoint(first, width, height)
+		if err != nil || pass {
+			return nil, fmt.Errorf("sgf: invalid point %q", value)
+		}
+		if !composed {
+			points = append(points, from)
+			continue
+		}
+
+		to, pass, err := parseSGFPoint(second, width, height)
+		if err != nil || pass {
+			return nil, fmt.Errorf("sgf: invalid point %q", value)
+		}
+		for x := minInt(from.X, to.X); x <= maxInt(from.X, to.X); x++ {
+			for y := minInt(from.Y, to.Y); y <= maxInt(from.Y, to.Y); y++ {
+				points = append(points, Point{x, y})
+			}
+		}
+	}
+	return points, nil
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// NewRecordFromGame builds a record of the moves played in g, with every
+// variation in its tree. The line to the current position is the main
+// line. Stones that were on the board before the first move, such as
+// handicap stones, are written as setup properties on the root node, and
+// later setups as nodes of their own.
+func NewRecordFromGame(g *Game) *Record {
+	root := sgf.NewNode(nil)
+	record := &Record{
+		Root:     root,
+		Width:    g.Board.Width,
+		Height:   g.Board.Height,
+		Handicap: g.Handicap,
+		Komi:     g.Rules.Komi,
+		Rules:    g.Rules.SGFName(),
+	}
+
+	current := make(map[*MoveNode]bool)
+	for n := g.Current; n != nil; n = n.Parent {
+		current[n] = true
+	}
+	children := func(n *MoveNode) []*MoveNode {
+		ordered := append([]*MoveNode(nil), n.Children...)
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return current[ordered[i]] && !current[ordered[j]]
+		})
+		return ordered
+	}
+
+	initial := g.Board.InitialGrid()
+	firstPlayer := g.CurrentTurn
+	if g.Tree != nil && len(g.Tree.Children) > 0 {
+		firstPlayer = children(g.Tree)[0].Player
+	}
+
+	for x := range initial {
+		for y, color := range initial[x] {
+			switch color {
+			case Black:
+				root.Add("AB", sgfPoint(Point{x, y}))
+			case White:
+				root.Add("AW", sgfPoint(Point{x, y}))
+			}
+		}
+	}
+	if firstPlayer == White {
+		root.Set("PL", "W")
+	}
+
+	var add func(parent *sgf.Node, n *MoveNode)
+	add = func(parent *sgf.Node, n *MoveNode) {
+		for _, child := range children(n) {
+			if child.Setup != nil {
+				add(record.AddSetup(parent, child.Setup), child)
+			} else {
+				add(record.AddMove(parent, child.Player, child.Move), child)
+			}
+		}
+	}
+	if g.Tree != nil {
+		add(root, g.Tree)
+	}
+
+	return record
+}
+
+// AddMove appends a move by color below parent and returns the new node.
+// A nil point records a pass. If parent already has children the move
+// starts a new variation.
+func (r *Record) AddMove(parent *sgf.Node, color Color, p *Point) *sgf.Node {
+	value := ""
+	if p != nil {
+		value = sgfPoint(*p)
+	}
+
+	node := sgf.NewNode(parent)
+	if color == White {
+		node.Set("W", value)
+	} else {
+		node.Set("B", value)
+	}
+	return node
+}
+
+// AddSetup appends a node below parent that sets each point in stones to
+// its color with AB, AW and AE properties, and returns it.
+func (r *Record) AddSetup(parent *sgf.Node, stones map[Point]Color) *sgf.Node {
+	ids := map[Color]string{Empty: "AE", Black: "AB", White: "AW"}
+	points := map[Color][]Point{}
+	for p, color := range stones {
+		points[color] = append(points[color], p)
+	}
+
+	node := sgf.NewNode(parent)
+	for _, color := range []Color{Empty, Black, White} {
+		r.Mark(node, ids[color], points[color]...)
+	}
+	return node
+}
+
+// Mark adds board markup to node. id is an SGF point-list property such as
+// TR (triangle), SQ (square), CR (circle), MA (cross), SL (selected),
+// TB (black territory) or TW (white territory).
+func (r *Record) Mark(node *sgf.Node, id string, points ...Point) {
+	sortPoints(points)
+	for _, p := range points {
+		node.Add(id, sgfPoint(p))
+	}
+}
+
+// Marked returns the points of a markup property on node.
+func (r *Record) Marked(node *sgf.Node, id string) ([]Point, error) {
+	return parseSGFPointList(node.Values(id), r.Width, r.Height)
+}
+
+// Label adds an LB text label at p.
+func (r *Record) Label(node *sgf.Node, p Point, text string) {
+	node.Add("LB", sgf.Compose(sgfPoint(p), text))
+}
+
+// Labels returns the LB labels on node.
+func (r *Record) Labels(node *sgf.Node) (map[Point]string, error) {
+	labels := make(map[Point]string)
+	for _, value := range node.Values("LB") {
+		first, text, _ := sgf.SplitCompose(value)
+		p, pass, err := parseSGFPoint(first, r.Width, r.Height)
+		if err != nil || pass {
+			return nil, fmt.Errorf("sgf: invalid label %q", value)
+		}
+		labels[p] = text
+	}
+	return labels, nil
+}
+
+// MarkTerritory writes territory as TB and TW markup on node.
+func (r *Record) MarkTerritory(node *sgf.Node, territory map[Point]Color) {
+	black, white := []Point{}, []Point{}
+	for p, owner := range territory {
+		switch owner {
+		case Black:
+			black = append(black, p)
+		case White:
+			white = append(white, p)
+		}
+	}
+	r.Mark(node, "TB", black...)
+	r.Mark(node, "TW", white...)
+}
+
+// LastNode returns the final node of the main line.
+func (r *Record) LastNode() *sgf.Node {
+	line := r.MainLine()
+	return line[len(line)-1]
+}
+
+// SGF returns the record as SGF text.
+func (r *Record) SGF() string {
+	r.updateRoot()
+	return r.Root.String()
+}
+
+// WriteSGF writes the record as SGF text to w.
+func (r *Record) WriteSGF(w io.Writer) error {
+	_, err := io.WriteString(w, r.SGF())
+	return err
+}
+
+// updateRoot writes the metadata fields to the root node, ahead of any
+// other root properties. The fields take precedence over root properties
+// read from a file.
+func (r *Record) updateRoot() {
+	size := strconv.Itoa(r.Width)
+	if r.Width != r.Height {
+		size += ":" + strconv.Itoa(r.Height)
+	}
+
+	application := r.Root.Get("AP")
+	if application == "" {
+		application = "GoSim"
+	}
+
+	handicap, timeLimit := "", ""
+	if r.Handicap >= 2 {
+		handicap = strconv.Itoa(r.Handicap)
+	}
+	if r.TimeLimit > 0 {
+		timeLimit = formatFloat(r.TimeLimit)
+	}
+
+	metadata := []sgf.Property{
+		{ID: "FF", Values: []string{"4"}},
+		{ID: "GM", Values: []string{"1"}},
+		{ID: "CA", Values: []string{"UTF-8"}},
+		{ID: "AP", Values: []string{application}},
+		{ID: "SZ", Values: []string{size}},
+		{ID: "KM", Values: []string{formatFloat(r.Komi)}},
+		{ID: "HA", Values: []string{handicap}},
+		{ID: "RU", Values: []string{r.Rules}},
+		{ID: "PB", Values: []string{r.BlackPlayer}},
+		{ID: "BR", Values: []string{r.BlackRank}},
+		{ID: "PW", Values: []string{r.WhitePlayer}},
+		{ID: "WR", Values: []string{r.WhiteRank}},
+		{ID: "RE", Values: []string{r.Result}},
+		{ID: "DT", Values: []string{r.Date}},
+		{ID: "EV", Values: []string{r.Event}},
+		{ID: "GN", Values: []string{r.GameName}},
+		{ID: "TM", Values: []string{timeLimit}},
+		{ID: "OT", Values: []string{r.Overtime}},
+	}
+
+	properties := []sgf.Property{}
+	isMetadata := make(map[string]bool)
+	for _, prop := range metadata {
+		isMetadata[prop.ID] = true
+		if prop.Values[0] != "" {
+			properties = append(properties, prop)
+		}
+	}
+	for _, prop := range r.Root.Properties {
+		if !isMetadata[prop.ID] {
+			properties = append(properties, prop)
+		}
+	}
+	r.Root.Properties = properties
+}
+
+func sortPoints(points []Point) {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Y != points[j].Y {
+			return points[i].Y < points[j].Y
+		}
+		return points[i].X < points[j].X
+	})
+}
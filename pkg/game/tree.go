@@ -0,0 +1,183 @@
+package game
+
+import "errors"
+
+var (
+	ErrNotInTree   = errors.New("node is not in this game's tree")
+	ErrHistoryLost = errors.New("board history does not reach the move tree's node")
+)
+
+// MoveNode is a move, pass or setup in a game's tree. Playing something
+// other than what followed before, for example after an undo, adds a new
+// child as a variation rather than discarding the old line.
+type MoveNode struct {
+	Move     *Point // nil for a pass or setup
+	Player   Color
+	Parent   *MoveNode
+	Children []*MoveNode
+
+	// Setup holds the stones a setup node changed outside play, with
+	// their new colors. It is nil for moves and passes, whose Player is
+	// the one who made them; a setup's is the player to move.
+	Setup map[Point]Color
+
+	// passed holds the pass flags from before the move, indexed by Color.
+	passed [3]bool
+
+	// next is the child Redo follows: the one last played or undone.
+	next *MoveNode
+}
+
+// child returns n's child for move by player, or for setup, or nil.
+func (n *MoveNode) child(move *Point, player Color, setup map[Point]Color) *MoveNode {
+	for _, c := range n.Children {
+		if c.Player == player && samePointer(c.Move, move) && sameSetup(c.Setup, setup) {
+			return c
+		}
+	}
+	return nil
+}
+
+// sameSetup reports whether a and b are both nil or set up the same
+// stones.
+func sameSetup(a, b map[Point]Color) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	for p, color := range a {
+		if other, ok := b[p]; !ok || other != color {
+			return false
+		}
+	}
+	return true
+}
+
+// path returns the nodes from the root of n's tree down to n.
+func (n *MoveNode) path() []*MoveNode {
+	var path []*MoveNode
+	for ; n != nil; n = n.Parent {
+		path = append(path, n)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// advance makes the child of Current for move by player, or for setup,
+// the current node, adding it if it has not been played before. It is
+// called once the move is known to be legal and before it changes the
+// game.
+func (g *Game) advance(move *Point, player Color, setup map[Point]Color) {
+	if g.Current == nil {
+		g.Tree = &MoveNode{}
+		g.Current = g.Tree
+	}
+	node := g.Current.child(move, player, setup)
+	if node == nil {
+		node = &MoveNode{Move: move, Player: player, Parent: g.Current, Setup: setup}
+		g.Current.Children = append(g.Current.Children, node)
+	}
+	node.passed = [3]bool{Black: g.Passed[Black], White: g.Passed[White]}
+	g.Current.next = node
+	g.Current = node
+}
+
+// Undo takes back the last move, pass or setup, restoring the board, the player
+// to move and the pass flags. It reports false if nothing has been played.
+func (g *Game) Undo() bool {
+	node := g.Current
+	if node == nil || node.Parent == nil || g.Board.Undo() == nil {
+		return false
+	}
+	g.Current = node.Parent
+	g.Current.next = node
+
+	g.CurrentTurn = node.Player
+	if node.Move != nil {
+		g.MoveCount--
+	}
+	g.Passed[Black] = node.passed[Black]
+	g.Passed[White] = node.passed[White]
+
+	// Moves are only played while the game is on, so it was on before
+	// this one.
+	g.IsOver = false
+	g.Winner = nil
+	g.Scoring = false
+	g.DeadStones = nil
+	g.Accepted = nil
+
+	return true
+}
+
+// Redo plays again the move last undone from the current node, or the one
+// last played from it after jumping back with GoTo. It reports false if
+// there is none.
+func (g *Game) Redo() bool {
+	if g.Current == nil || g.Current.next == nil {
+		return false
+	}
+	return g.replay(g.Current.next) == nil
+}
+
+// GoTo makes node the current position, undoing back to where its line
+// leaves the current one and replaying the rest, node included. Replaying
+// a line that ends in two passes ends the game, or starts scoring, as it
+// did the first time.
+func (g *Game) GoTo(node *MoveNode) error {
+	path := node.path()
+	if path[0] != g.Tree {
+		return ErrNotInTree
+	}
+
+	onPath := make(map[*MoveNode]bool, len(path))
+	for _, n := range path {
+		onPath[n] = true
+	}
+	for !onPath[g.Current] {
+		if !g.Undo() {
+			return ErrHistoryLost
+		}
+	}
+	// node itself is replayed too, so that the game is in the state its
+	// move left it in even if play was resumed after it.
+	if g.Current == node && node.Parent != nil && !g.Undo() {
+		return ErrHistoryLost
+	}
+
+	depth := 0
+	for path[depth] != g.Current {
+		depth++
+	}
+	for _, n := range path[depth+1:] {
+		if err := g.replay(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replay plays node, a child of the current node, again. Play may have
+// been resumed after scoring, or a loaded record may continue after both
+// players passed, so those are left first, and the turn is given to the
+// player who made the move.
+func (g *Game) replay(node *MoveNode) error {
+	if g.Scoring {
+		g.ResumePlay(OpponentColor(node.Player))
+	}
+	if g.IsOver {
+		g.IsOver = false
+		g.Winner = nil
+	}
+	g.CurrentTurn = node.Player
+
+	if node.Setup != nil {
+		g.SetUp(node.Setup)
+		return nil
+	}
+	if node.Move == nil {
+		return g.Pass(node.Player)
+	}
+	return g.MakeMove(*node.Move, node.Player)
+}
@@ -0,0 +1,212 @@
+package game
+
+import "strings"
+
+// TrompTaylorScoring counts the board as it stands by the Tromp-Taylor
+// rules: no stones are removed as dead.
+const TrompTaylorScoring ScoringMethod = "tromp-taylor"
+
+// TrompTaylorGame is a literal implementation of the Tromp-Taylor rules,
+// kept apart from Board and Game so that it can check them. It works on a
+// plain grid without chains, hashes or incremental liberties.
+type TrompTaylorGame struct {
+	Width  int
+	Height int
+	Grid   [][]Color
+	ToMove Color
+	Passes int
+
+	// seen holds every position that has occurred, for positional
+	// superko.
+	seen map[string]bool
+}
+
+// NewTrompTaylorGame starts a game on an empty width x height board with
+// Black to move.
+func NewTrompTaylorGame(width, height int) *TrompTaylorGame {
+	t := &TrompTaylorGame{
+		Width:  width,
+		Height: height,
+		Grid:   make([][]Color, width),
+		ToMove: Black,
+		seen:   make(map[string]bool),
+	}
+	for x := range t.Grid {
+		t.Grid[x] = make([]Color, height)
+	}
+	t.seen[t.position(t.Grid)] = true
+	return t
+}
+
+// IsOver reports whether the game has ended with two consecutive passes.
+func (t *TrompTaylorGame) IsOver() bool {
+	return t.Passes >= 2
+}
+
+// Pass passes for the player to move.
+func (t *TrompTaylorGame) Pass() error {
+	if t.IsOver() {
+		return ErrGameOver
+	}
+	t.Passes++
+	t.ToMove = OpponentColor(t.ToMove)
+	return nil
+}
+
+// Play places a stone for the player to move at p, clears the opponent's
+// stones that no longer reach an empty point, then the player's own. A
+// move that recreates an earlier position is illegal.
+func (t *TrompTaylorGame) Play(p Point) error {
+	if t.IsOver() {
+		return ErrGameOver
+	}
+	next, err := t.After(p)
+	if err != nil {
+		return err
+	}
+
+	t.Grid = next
+	t.seen[t.position(next)] = true
+	t.Passes = 0
+	t.ToMove = OpponentColor(t.ToMove)
+	return nil
+}
+
+// After returns the grid that playing at p would give, or the reason the
+// move is illegal, without changing the game.
+func (t *TrompTaylorGame) After(p Point) ([][]Color, error) {
+	if p.X < 0 || p.X >= t.Width || p.Y < 0 || p.Y >= t.Height {
+		return nil, ErrInvalidMove
+	}
+	if t.Grid[p.X][p.Y] != Empty {
+		return nil, ErrPositionOccupied
+	}
+
+	next := make([][]Color, t.Width)
+	for x := range next {
+		next[x] = append([]Color(nil), t.Grid[x]...)
+	}
+	next[p.X][p.Y] = t.ToMove
+	t.clear(next, OpponentColor(t.ToMove))
+	t.clear(next, t.ToMove)
+
+	if t.seen[t.position(next)] {
+		return nil, ErrSuperkoViolation
+	}
+	return next, nil
+}
+
+// Score scores the position by area with komi.
+func (t *TrompTaylorGame) Score(komi float64) *Score {
+	return trompTaylorScore(t.Grid, komi)
+}
+
+// clear empties every stone of color in grid that does not reach an
+// empty point.
+func (t *TrompTaylorGame) clear(grid [][]Color, color Color) {
+	reaches := reachable(grid, color, Empty)
+	for x := range grid {
+		for y := range grid[x] {
+			if grid[x][y] == color && !reaches[x][y] {
+				grid[x][y] = Empty
+			}
+		}
+	}
+}
+
+func (t *TrompTaylorGame) position(grid [][]Color) string {
+	var sb strings.Builder
+	for x := range grid {
+		for y := range grid[x] {
+			sb.WriteByte(byte('0' + grid[x][y]))
+		}
+	}
+	return sb.String()
+}
+
+// TrompTaylorScore scores board by the Tromp-Taylor rules: a player's
+// score is the number of points of their color plus the empty points
+// that reach only their color, and White adds komi.
+func TrompTaylorScore(board *Board, komi float64) *Score {
+	score := trompTaylorScore(board.Grid, komi)
+	score.Captures = board.Captures
+	return score
+}
+
+func trompTaylorScore(grid [][]Color, komi float64) *Score {
+	score := &Score{
+		Territory: map[Color]int{Black: 0, White: 0},
+		Captures:  map[Color]int{Black: 0, White: 0},
+		Komi:      komi,
+		Method:    TrompTaylorScoring,
+	}
+
+	reachesBlack := reachable(grid, Empty, Black)
+	reachesWhite := reachable(grid, Empty, White)
+	stones := map[Color]int{}
+	for x := range grid {
+		for y := range grid[x] {
+			switch {
+			case grid[x][y] != Empty:
+				stones[grid[x][y]]++
+			case reachesBlack[x][y] && !reachesWhite[x][y]:
+				score.Territory[Black]++
+			case reachesWhite[x][y] && !reachesBlack[x][y]:
+				score.Territory[White]++
+			}
+		}
+	}
+
+	score.Black = float64(stones[Black] + score.Territory[Black])
+	score.White = float64(stones[White]+score.Territory[White]) + komi
+	score.determineWinner()
+	return score
+}
+
+// reachable marks the points of color from which a path through points
+// of color leads to a point of target, as the Tromp-Taylor rules define
+// reaching.
+func reachable(grid [][]Color, color, target Color) [][]bool {
+	reaches := make([][]bool, len(grid))
+	for x := range reaches {
+		reaches[x] = make([]bool, len(grid[x]))
+	}
+
+	// Spread outwards from the points of color next to target.
+	var queue []Point
+	for x := range grid {
+		for y := range grid[x] {
+			if grid[x][y] != color {
+				continue
+			}
+			for _, n := range gridNeighbors(grid, Point{x, y}) {
+				if grid[n.X][n.Y] == target {
+					reaches[x][y] = true
+					queue = append(queue, Point{x, y})
+					break
+				}
+			}
+		}
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, n := range gridNeighbors(grid, p) {
+			if grid[n.X][n.Y] == color && !reaches[n.X][n.Y] {
+				reaches[n.X][n.Y] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return reaches
+}
+
+func gridNeighbors(grid [][]Color, p Point) []Point {
+	neighbors := make([]Point, 0, 4)
+	for _, n := range []Point{{p.X - 1, p.Y}, {p.X + 1, p.Y}, {p.X, p.Y - 1}, {p.X, p.Y + 1}} {
+		if n.X >= 0 && n.X < len(grid) && n.Y >= 0 && n.Y < len(grid[n.X]) {
+			neighbors = append(neighbors, n)
+		}
+	}
+	return neighbors
+}
@@ -0,0 +1,107 @@
+package game
+
+// Zobrist keys are derived from a fixed mixing function rather than a
+// random table so that hashes are identical across processes and do not
+// depend on the board size.
+
+const (
+	zobristSeed uint64 = 0x9e3779b97f4a7c15
+)
+
+func splitmix64(x uint64) uint64 {
+	x += zobristSeed
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// zobristKey returns the key for a stone of the given color at p.
+func zobristKey(p Point, color Color) uint64 {
+	return splitmix64(uint64(p.X)<<24 | uint64(p.Y)<<4 | uint64(color))
+}
+
+// zobristTurnKey returns the key that distinguishes the player to move,
+// used for situational superko.
+func zobristTurnKey(color Color) uint64 {
+	return splitmix64(uint64(1)<<40 | uint64(color))
+}
+
+// HashAfterMove returns the Zobrist hash of the position that would
+// result from color playing at p, including any captures. It does not
+// check legality.
+func (b *Board) HashAfterMove(p Point, color Color) uint64 {
+	if b.IsSuicide(p, color) {
+		// The new stone and the chains it joins are removed again.
+		hash := b.Hash
+		removed := make([]*chain, 0, 4)
+		for _, neighbor := range b.GetNeighbors(p) {
+			c := b.chainAt(neighbor)
+			if c == nil || c.color != color || containsChain(removed, c) {
+				continue
+			}
+			removed = append(removed, c)
+			for _, stone := range c.stones {
+				hash ^= zobristKey(stone, color)
+			}
+		}
+		return hash
+	}
+
+	hash := b.Hash ^ zobristKey(p, color)
+	opponent := OpponentColor(color)
+
+	captured := make([]*chain, 0, 4)
+	for _, neighbor := range b.GetNeighbors(p) {
+		c := b.chainAt(neighbor)
+		if c == nil || c.color != opponent || len(c.liberties) != 1 || containsChain(captured, c) {
+			continue
+		}
+		captured = append(captured, c)
+		for _, stone := range c.stones {
+			hash ^= zobristKey(stone, opponent)
+		}
+	}
+
+	return hash
+}
+
+func containsChain(chains []*chain, c *chain) bool {
+	for _, other := range chains {
+		if other == c {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSeenPosition reports whether the position with the given hash
+// occurred earlier in the game.
+func (b *Board) HasSeenPosition(hash uint64) bool {
+	return hash == b.Hash || b.positions[hash] > 0
+}
+
+// HasSeenSituation reports whether the position with the given hash
+// occurred earlier in the game with toMove as the player to move.
+func (b *Board) HasSeenSituation(hash uint64, toMove Color) bool {
+	return b.situations[hash^zobristTurnKey(toMove)] > 0
+}
+
+func (b *Board) recordPosition(hash uint64, toMove Color) {
+	b.positions[hash]++
+	b.situations[hash^zobristTurnKey(toMove)]++
+}
+
+func (b *Board) forgetPosition(hash uint64, toMove Color) {
+	if b.positions[hash] <= 1 {
+		delete(b.positions, hash)
+	} else {
+		b.positions[hash]--
+	}
+
+	key := hash ^ zobristTurnKey(toMove)
+	if b.situations[key] <= 1 {
+		delete(b.situations, key)
+	} else {
+		b.situations[key]--
+	}
+}
@@ -0,0 +1,463 @@
+package gtp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+var (
+	ErrTimeout      = errors.New("gtp: engine timed out")
+	ErrEngineExited = errors.New("gtp: engine exited")
+	ErrResign       = fmt.Errorf("gtp: %w", game.ErrEngineResigned)
+	ErrBadResponse  = errors.New("gtp: malformed response")
+	ErrRectangular  = errors.New("gtp: rectangular boards are not supported")
+)
+
+// EngineError is a failure response ("? message") from the engine.
+type EngineError struct {
+	Command string
+	Message string
+}
+
+func (e *EngineError) Error() string {
+	return fmt.Sprintf("gtp: %s: %s", e.Command, e.Message)
+}
+
+// Client drives an external GTP engine running as a subprocess. Its
+// GetMove method matches game.AI, so an engine such as GNU Go can take a
+// seat wherever the built-in AI can.
+type Client struct {
+	Color       game.Color
+	Path        string
+	Args        []string
+	Timeout     time.Duration
+	MoveTimeout time.Duration
+	MaxRestarts int
+
+	mu       sync.Mutex
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	lines    chan string
+	done     chan struct{}
+	nextID   int
+	restarts int
+
+	// The position the engine currently holds, so that only new moves
+	// need to be sent.
+	syncedSize     int
+	syncedKomi     float64
+	syncedRules    string
+	syncedMoves    []move
+	moveTime       time.Duration
+	syncedMoveTime time.Duration
+}
+
+// NewClient creates a client that plays color using the engine at path.
+// The engine is started on first use.
+func NewClient(color game.Color, path string, args ...string) *Client {
+	return &Client{
+		Color:       color,
+		Path:        path,
+		Args:        args,
+		Timeout:     10 * time.Second,
+		MoveTimeout: 60 * time.Second,
+		MaxRestarts: 3,
+	}
+}
+
+// Start launches the engine process if it is not already running.
+func (c *Client) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.start()
+}
+
+func (c *Client) start() error {
+	if c.cmd != nil {
+		return nil
+	}
+
+	cmd := exec.Command(c.Path, c.Args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	lines := make(chan string, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	c.cmd = cmd
+	c.stdin = stdin
+	c.lines = lines
+	c.done = done
+	c.syncedMoves = nil
+	c.syncedSize = 0
+	return nil
+}
+
+// stop kills the engine process. The next command restarts it.
+func (c *Client) stop() {
+	if c.cmd == nil {
+		return
+	}
+	close(c.done)
+	c.stdin.Close()
+	c.cmd.Process.Kill()
+	c.cmd.Wait()
+	c.cmd = nil
+}
+
+// Close asks the engine to quit and waits for it to exit, killing it if
+// it does not respond.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cmd == nil {
+		return nil
+	}
+	_, err := c.send(context.Background(), c.Timeout, "quit")
+	c.stop()
+	return err
+}
+
+// Send runs a single command and returns the engine's response.
+func (c *Client) Send(command string, args ...string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.start(); err != nil {
+		return "", err
+	}
+	return c.send(context.Background(), c.Timeout, command, args...)
+}
+
+func (c *Client) send(ctx context.Context, timeout time.Duration, command string, args ...string) (string, error) {
+	c.nextID++
+	id := strconv.Itoa(c.nextID)
+	line := strings.Join(append([]string{id, command}, args...), " ") + "\n"
+
+	if _, err := io.WriteString(c.stdin, line); err != nil {
+		c.stop()
+		return "", ErrEngineExited
+	}
+
+	response, err := c.readResponse(ctx, timeout, id)
+	if err != nil {
+		// After a timeout, cancellation or framing error the engine's
+		// output can no longer be matched to commands, so start afresh.
+		c.stop()
+		return "", err
+	}
+
+	if strings.HasPrefix(response, "?") {
+		return "", &EngineError{Command: command, Message: strings.TrimSpace(response[1+len(id):])}
+	}
+	return strings.TrimSpace(response[1+len(id):]), nil
+}
+
+// readResponse collects lines up to the blank line that ends a response.
+func (c *Client) readResponse(ctx context.Context, timeout time.Duration, id string) (string, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	lines := []string{}
+	for {
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				return "", ErrEngineExited
+			}
+			line = strings.TrimRight(line, "\r")
+
+			if len(lines) == 0 {
+				if line == "" {
+					continue
+				}
+				if !hasID(line, id) {
+					return "", ErrBadResponse
+				}
+			} else if line == "" {
+				return strings.Join(lines, "\n"), nil
+			}
+			lines = append(lines, line)
+
+		case <-timer.C:
+			return "", ErrTimeout
+
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// hasID reports whether line starts a response to the command with id.
+func hasID(line, id string) bool {
+	if line[0] != '=' && line[0] != '?' {
+		return false
+	}
+	rest := line[1:]
+	return strings.HasPrefix(rest, id) && (len(rest) == len(id) || rest[len(id)] == ' ')
+}
+
+// GetMove asks the engine for a move in g. It returns nil for a pass, and
+// also when the engine resigns or fails, after logging why.
+func (c *Client) GetMove(g *game.Game) *game.Point {
+	return c.GenerateMove(context.Background(), g, c.Color)
+}
+
+// Name returns the engine's executable name.
+func (c *Client) Name() string {
+	return filepath.Base(c.Path)
+}
+
+// GenerateMove makes the client a game.Engine. It returns nil for a pass,
+// and also when the engine resigns or fails, after logging why; callers
+// that need to tell those apart use TryGenerateMove. If ctx has a
+// deadline the engine is given most of the remaining time as byo-yomi of
+// one move per period; engines that do not support time_settings ignore
+// it.
+func (c *Client) GenerateMove(ctx context.Context, g *game.Game, color game.Color) *game.Point {
+	move, err := c.TryGenerateMove(ctx, g, color)
+	if err != nil {
+		log.Printf("GTP engine %s: %v", c.Path, err)
+		return nil
+	}
+	return move
+}
+
+// TryGenerateMove makes the client a game.FallibleEngine. It returns
+// ErrResign, which wraps game.ErrEngineResigned, when the engine resigns,
+// and the error when it fails for good.
+func (c *Client) TryGenerateMove(ctx context.Context, g *game.Game, color game.Color) (*game.Point, error) {
+	c.mu.Lock()
+	c.Color = color
+	c.mu.Unlock()
+
+	return c.GenMoveContext(ctx, g)
+}
+
+// RegisterEngine registers the GTP engine at path with game's engine
+// registry, so that it can be chosen by name like the built-in AI. Each
+// engine instance runs its own process; call Close when done with it.
+func RegisterEngine(name, path string, args ...string) {
+	info := game.EngineInfo{
+		Name:         name,
+		Description:  "GTP engine " + filepath.Base(path),
+		Capabilities: []string{game.CapabilityTimeBudget, game.CapabilityExternal},
+	}
+	game.RegisterEngine(info, func() game.Engine {
+		return NewClient(game.Black, path, args...)
+	})
+}
+
+// GenMove brings the engine up to date with g and asks it for a move. If
+// the engine crashes or times out it is restarted, up to MaxRestarts
+// times over the client's lifetime.
+func (c *Client) GenMove(g *game.Game) (*game.Point, error) {
+	return c.GenMoveContext(context.Background(), g)
+}
+
+// GenMoveContext is GenMove with a context. If ctx is done before the
+// engine answers the engine is stopped, to be restarted by the next
+// command, and ctx's error is returned.
+func (c *Client) GenMoveContext(ctx context.Context, g *game.Game) (*game.Point, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Leave a margin for the engine's overhead, in whole seconds as GTP
+	// expects.
+	c.moveTime = 0
+	if deadline, ok := ctx.Deadline(); ok {
+		c.moveTime = time.Until(deadline) * 4 / 5
+		c.moveTime = maxDuration(c.moveTime.Truncate(time.Second), time.Second)
+	}
+
+	for {
+		move, err := c.genMove(ctx, g)
+		if err == nil || !c.restartable(err) || c.restarts >= c.MaxRestarts {
+			return move, err
+		}
+		c.restarts++
+		log.Printf("GTP engine %s: %v, restarting", c.Path, err)
+	}
+}
+
+func (c *Client) restartable(err error) bool {
+	return errors.Is(err, ErrEngineExited) || errors.Is(err, ErrTimeout) || errors.Is(err, ErrBadResponse)
+}
+
+func (c *Client) genMove(ctx context.Context, g *game.Game) (*game.Point, error) {
+	if !g.Board.IsSquare() {
+		return nil, ErrRectangular
+	}
+	if err := c.start(); err != nil {
+		return nil, err
+	}
+	if err := c.sync(ctx, g); err != nil {
+		return nil, err
+	}
+
+	response, err := c.send(ctx, c.MoveTimeout, "genmove", colorName(c.Color))
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(response, "resign") {
+		return nil, ErrResign
+	}
+
+	p, pass, err := ParseVertex(response, g.Board.Width)
+	if err != nil {
+		return nil, fmt.Errorf("gtp: genmove: %w", err)
+	}
+	c.syncedMoves = append(c.syncedMoves, move{color: c.Color, point: vertexPoint(p, pass)})
+	if pass {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+// sync sends whatever the engine is missing of g's position. Setup stones
+// are sent as moves ahead of the game's own moves.
+func (c *Client) sync(ctx context.Context, g *game.Game) error {
+	moves := gameMoves(g)
+	rules := ""
+	if rs, err := game.LookupRuleset(g.Rules.Name); err == nil {
+		rules = rs.GTPName
+	}
+
+	if c.syncedSize != g.Board.Width || c.syncedKomi != g.Rules.Komi || c.syncedRules != rules || !isPrefix(c.syncedMoves, moves) {
+		if rules != "" {
+			// kgs-rules is an extension; engines without it keep their own rules.
+			_, err := c.send(ctx, c.Timeout, "kgs-rules", rules)
+			var engineErr *EngineError
+			if err != nil && !errors.As(err, &engineErr) {
+				return err
+			}
+		}
+		commands := [][]string{
+			{"boardsize", strconv.Itoa(g.Board.Width)},
+			{"clear_board"},
+			{"komi", strconv.FormatFloat(g.Rules.Komi, 'f', -1, 64)},
+		}
+		for _, command := range commands {
+			if _, err := c.send(ctx, c.Timeout, command[0], command[1:]...); err != nil {
+				return err
+			}
+		}
+		c.syncedSize = g.Board.Width
+		c.syncedKomi = g.Rules.Komi
+		c.syncedRules = rules
+		c.syncedMoves = nil
+		c.syncedMoveTime = 0
+	}
+
+	if c.moveTime > 0 && c.moveTime != c.syncedMoveTime {
+		seconds := strconv.Itoa(int(c.moveTime.Seconds()))
+		_, err := c.send(ctx, c.Timeout, "time_settings", "0", seconds, "1")
+		var engineErr *EngineError
+		if err != nil && !errors.As(err, &engineErr) {
+			return err
+		}
+		c.syncedMoveTime = c.moveTime
+	}
+
+	for _, m := range moves[len(c.syncedMoves):] {
+		if _, err := c.send(ctx, c.Timeout, "play", colorName(m.color), FormatVertex(m.point, g.Board.Width)); err != nil {
+			return err
+		}
+		c.syncedMoves = append(c.syncedMoves, m)
+	}
+	return nil
+}
+
+// gameMoves lists the stones on g's board before its first move, or after
+// its last setup since GTP cannot remove stones, followed by the moves
+// played since.
+func gameMoves(g *game.Game) []move {
+	board := g.Board
+	initial, history := board.SetupGrid()
+
+	moves := []move{}
+	for y := 0; y < board.Height; y++ {
+		for x := 0; x < board.Width; x++ {
+			if color := initial[x][y]; color != game.Empty {
+				moves = append(moves, move{color: color, point: &game.Point{X: x, Y: y}})
+			}
+		}
+	}
+	for _, state := range history {
+		moves = append(moves, move{color: state.Player, point: state.Move})
+	}
+	return moves
+}
+
+func isPrefix(prefix, moves []move) bool {
+	if len(prefix) > len(moves) {
+		return false
+	}
+	for i, m := range prefix {
+		if m.color != moves[i].color || !samePoint(m.point, moves[i].point) {
+			return false
+		}
+	}
+	return true
+}
+
+func samePoint(a, b *game.Point) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func vertexPoint(p game.Point, pass bool) *game.Point {
+	if pass {
+		return nil
+	}
+	return &p
+}
+
+func colorName(color game.Color) string {
+	if color == game.White {
+		return "white"
+	}
+	return "black"
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,703 @@
+// Package gtp implements the Go Text Protocol version 2, so that GoSim's
+// AI can be driven by GUIs and tournament tools.
+package gtp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrSyntax         = errors.New("syntax error")
+	ErrIllegalMove    = errors.New("illegal move")
+	ErrBoardSize      = errors.New("unacceptable size")
+	ErrBoardNotEmpty  = errors.New("board not empty")
+	ErrHandicap       = errors.New("invalid number of stones")
+	ErrVertexList     = errors.New("bad vertex list")
+	ErrCannotUndo     = errors.New("cannot undo")
+	ErrUnknownRules   = errors.New("unknown rules")
+)
+
+// TimeSettings holds the time control given by time_settings, in seconds.
+type TimeSettings struct {
+	MainTime      int
+	ByoYomiTime   int
+	ByoYomiStones int
+}
+
+// TimeLeft holds the clock state given by time_left.
+type TimeLeft struct {
+	Time   int
+	Stones int
+}
+
+type move struct {
+	color game.Color
+	point *game.Point
+}
+
+type handler func(args []string) (string, error)
+
+// Engine answers GTP commands using game.Game for the rules and game.AI
+// for move generation.
+type Engine struct {
+	Name       string
+	Version    string
+	Difficulty string
+	Playouts   int
+	// Seed, if not zero, makes the AI's moves reproducible.
+	Seed int64
+	// ResignMargin, if positive, makes genmove resign once the AI is
+	// more than this many points behind.
+	ResignMargin float64
+	// Ruleset names the preset for new games; empty means Chinese
+	// scoring with the default rules.
+	Ruleset string
+
+	TimeSettings TimeSettings
+	TimeLeft     map[game.Color]TimeLeft
+
+	size     int
+	komi     float64
+	game     *game.Game
+	handicap []game.Point
+	moves    []move
+	players  map[game.Color]*game.AI
+	handlers map[string]handler
+}
+
+// NewEngine creates an engine on an empty 19x19 board whose AI plays at
+// the given difficulty.
+func NewEngine(difficulty string) *Engine {
+	e := &Engine{
+		Name:       "GoSim",
+		Version:    "1.0.0",
+		Difficulty: difficulty,
+		TimeLeft:   make(map[game.Color]TimeLeft),
+		players:    make(map[game.Color]*game.AI),
+		size:       19,
+		komi:       6.5,
+	}
+	e.reset()
+
+	e.handlers = map[string]handler{
+		"protocol_version":       e.protocolVersion,
+		"name":                   e.name,
+		"version":                e.version,
+		"known_command":          e.knownCommand,
+		"list_commands":          e.listCommands,
+		"quit":                   e.quit,
+		"boardsize":              e.boardSize,
+		"clear_board":            e.clearBoard,
+		"komi":                   e.setKomi,
+		"play":                   e.play,
+		"genmove":                e.genMove,
+		"undo":                   e.undo,
+		"showboard":              e.showBoard,
+		"final_score":            e.finalScore,
+		"final_status_list":      e.finalStatusList,
+		"fixed_handicap":         e.fixedHandicap,
+		"place_free_handicap":    e.placeFreeHandicap,
+		"set_free_handicap":      e.setFreeHandicap,
+		"time_settings":          e.timeSettings,
+		"time_left":              e.timeLeft,
+		"kgs-rules":              e.kgsRules,
+		"gogui-analyze_commands": e.analyzeCommands,
+		"gosim-ownership":        e.ownership,
+		"gosim-score_estimate":   e.scoreEstimate,
+	}
+
+	return e
+}
+
+// Game returns the game the engine is playing.
+func (e *Engine) Game() *game.Game {
+	return e.game
+}
+
+// Run reads commands from r and writes responses to w until quit is
+// received or r is exhausted.
+func (e *Engine) Run(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := preprocess(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		id, command, args := parseCommand(line)
+		result, err := e.Execute(command, args)
+
+		var response string
+		if err != nil {
+			response = "?" + id + " " + err.Error() + "\n\n"
+		} else {
+			response = "=" + id + " " + result + "\n\n"
+		}
+		if _, err := io.WriteString(w, response); err != nil {
+			return err
+		}
+
+		if command == "quit" && err == nil {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// Execute runs a single command and returns its result.
+func (e *Engine) Execute(command string, args []string) (string, error) {
+	h, ok := e.handlers[command]
+	if !ok {
+		return "", ErrUnknownCommand
+	}
+	return h(args)
+}
+
+// preprocess removes comments and control characters and converts tabs
+// to spaces, as required by the protocol.
+func preprocess(line string) string {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+
+	var b strings.Builder
+	for _, c := range line {
+		switch {
+		case c == '\t':
+			b.WriteByte(' ')
+		case c < 32 || c == 127:
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func parseCommand(line string) (id, command string, args []string) {
+	fields := strings.Fields(line)
+	if _, err := strconv.Atoi(fields[0]); err == nil {
+		id = fields[0]
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return id, "", nil
+	}
+	return id, strings.ToLower(fields[0]), fields[1:]
+}
+
+func (e *Engine) protocolVersion(args []string) (string, error) {
+	return "2", nil
+}
+
+func (e *Engine) name(args []string) (string, error) {
+	return e.Name, nil
+}
+
+func (e *Engine) version(args []string) (string, error) {
+	return e.Version, nil
+}
+
+func (e *Engine) knownCommand(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", ErrSyntax
+	}
+	_, ok := e.handlers[args[0]]
+	return strconv.FormatBool(ok), nil
+}
+
+func (e *Engine) listCommands(args []string) (string, error) {
+	names := make([]string, 0, len(e.handlers))
+	for name := range e.handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, "\n"), nil
+}
+
+func (e *Engine) quit(args []string) (string, error) {
+	return "", nil
+}
+
+func (e *Engine) boardSize(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", ErrSyntax
+	}
+	size, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", ErrSyntax
+	}
+	if size < 2 || size > MaxBoardSize {
+		return "", ErrBoardSize
+	}
+
+	e.size = size
+	e.reset()
+	return "", nil
+}
+
+func (e *Engine) clearBoard(args []string) (string, error) {
+	e.reset()
+	// Fresh players, so that a seeded engine plays every game the same.
+	e.players = make(map[game.Color]*game.AI)
+	return "", nil
+}
+
+func (e *Engine) reset() {
+	e.game = game.NewGame(e.size)
+	if rs, err := game.LookupRuleset(e.Ruleset); err == nil {
+		e.game.Rules = rs.Rules()
+	} else {
+		e.game.Rules.ScoringMethod = game.ChineseScoring
+	}
+	e.game.Rules.Komi = e.komi
+	e.handicap = nil
+	e.moves = nil
+}
+
+func (e *Engine) setKomi(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", ErrSyntax
+	}
+	komi, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return "", ErrSyntax
+	}
+	e.komi = komi
+	e.game.Rules.Komi = komi
+	return "", nil
+}
+
+// kgsRules sets the ruleset. As on KGS it is meant for the next game,
+// but it also applies at once while the board is still empty.
+func (e *Engine) kgsRules(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", ErrSyntax
+	}
+	rs, err := game.LookupRuleset(args[0])
+	if err != nil {
+		return "", ErrUnknownRules
+	}
+	e.Ruleset = rs.Name
+	if len(e.moves) == 0 && len(e.handicap) == 0 {
+		e.reset()
+	}
+	return "", nil
+}
+
+func (e *Engine) play(args []string) (string, error) {
+	if len(args) != 2 {
+		return "", ErrSyntax
+	}
+	color, err := ParseColor(args[0])
+	if err != nil {
+		return "", ErrSyntax
+	}
+	p, pass, err := ParseVertex(args[1], e.size)
+	if err != nil {
+		return "", ErrSyntax
+	}
+
+	var point *game.Point
+	if !pass {
+		point = &p
+	}
+	if err := e.playMove(color, point); err != nil {
+		return "", ErrIllegalMove
+	}
+	return "", nil
+}
+
+// playMove plays a move for color whether or not it is that color's turn,
+// since GTP controllers may play several moves for one side.
+func (e *Engine) playMove(color game.Color, p *game.Point) error {
+	g := e.game
+	if g.IsOver {
+		g.IsOver = false
+		g.Winner = nil
+	}
+	g.CurrentTurn = color
+
+	var err error
+	if p == nil {
+		err = g.Pass(color)
+	} else {
+		err = g.MakeMove(*p, color)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.moves = append(e.moves, move{color: color, point: p})
+	return nil
+}
+
+func (e *Engine) genMove(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", ErrSyntax
+	}
+	color, err := ParseColor(args[0])
+	if err != nil {
+		return "", ErrSyntax
+	}
+
+	e.game.CurrentTurn = color
+	ai := e.player(color)
+	if ai.ShouldResign(context.Background(), e.game, color) {
+		return "resign", nil
+	}
+	ctx := context.Background()
+	if ai.MoveTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ai.MoveTime)
+		defer cancel()
+	}
+	p := ai.GetMoveContext(ctx, e.game)
+
+	if err := e.playMove(color, p); err != nil {
+		return "", err
+	}
+	return FormatVertex(p, e.size), nil
+}
+
+// player returns the AI for color, keeping it between moves so that a
+// search tree can be reused.
+func (e *Engine) player(color game.Color) *game.AI {
+	ai, ok := e.players[color]
+	if !ok || ai.Difficulty != e.Difficulty {
+		ai = e.newAI(color)
+		e.players[color] = ai
+	}
+	ai.Playouts = e.Playouts
+	ai.MoveTime = e.moveTime(color)
+	ai.ResignMargin = e.ResignMargin
+	return ai
+}
+
+func (e *Engine) newAI(color game.Color) *game.AI {
+	ai := game.NewAI(color, e.Difficulty)
+	if e.Seed != 0 {
+		ai.SetSeed(e.Seed + int64(color))
+	}
+	return ai
+}
+
+// moveTime spreads the time left on color's clock over the moves still to
+// play in the period, or returns 0 if the game is untimed.
+func (e *Engine) moveTime(color game.Color) time.Duration {
+	left, ok := e.TimeLeft[color]
+	if !ok || left.Time <= 0 {
+		return 0
+	}
+	moves := left.Stones
+	if moves == 0 {
+		moves = 30
+	}
+	return time.Duration(left.Time) * time.Second / time.Duration(moves)
+}
+
+// undo takes back the last move by replaying the game without it.
+func (e *Engine) undo(args []string) (string, error) {
+	if len(e.moves) == 0 {
+		return "", ErrCannotUndo
+	}
+
+	moves := e.moves[:len(e.moves)-1]
+	handicap := e.handicap
+	e.reset()
+	e.placeHandicap(handicap)
+	for _, m := range moves {
+		if err := e.playMove(m.color, m.point); err != nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+func (e *Engine) showBoard(args []string) (string, error) {
+	return "\n" + e.game.Board.String(), nil
+}
+
+func (e *Engine) result() *game.GameResult {
+	return game.GetGameResult(e.game, e.game.Rules.ScoringMethod, e.game.Rules.Komi)
+}
+
+func (e *Engine) finalScore(args []string) (string, error) {
+	result := e.result().Score.GetResult()
+	if result == "Draw" {
+		return "0", nil
+	}
+	return result, nil
+}
+
+func (e *Engine) finalStatusList(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", ErrSyntax
+	}
+
+	status := strings.ToLower(args[0])
+	if status != "alive" && status != "dead" && status != "seki" {
+		return "", ErrSyntax
+	}
+
+	board := e.game.Board
+	dead := game.MarkDeadStones(board)
+	seki := make(map[game.Point]bool)
+	for _, s := range game.FindSeki(board) {
+		for _, stone := range s.Stones {
+			seki[stone] = true
+		}
+	}
+	visited := make(map[game.Point]bool)
+	lines := []string{}
+
+	for y := 0; y < board.Height; y++ {
+		for x := 0; x < board.Width; x++ {
+			p := game.Point{X: x, Y: y}
+			if visited[p] || board.GetColor(p) == game.Empty {
+				continue
+			}
+
+			group := board.GetGroup(p)
+			vertices := make([]string, 0, len(group))
+			for _, stone := range group {
+				visited[stone] = true
+				vertices = append(vertices, FormatVertex(&stone, board.Height))
+			}
+
+			groupStatus := "alive"
+			if dead.Stones[p] {
+				groupStatus = "dead"
+			} else if seki[p] {
+				groupStatus = "seki"
+			}
+			if groupStatus == status {
+				lines = append(lines, strings.Join(vertices, " "))
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (e *Engine) isEmpty() bool {
+	board := e.game.Board
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			if board.Grid[x][y] != game.Empty {
+				return false
+			}
+		}
+	}
+	return len(e.moves) == 0
+}
+
+func (e *Engine) placeHandicap(points []game.Point) {
+	e.handicap = points
+	if len(points) > 0 {
+		// The controller sets komi for handicap games itself.
+		e.game.PlaceHandicap(points)
+		e.game.Rules.Komi = e.komi
+	}
+}
+
+func (e *Engine) formatVertices(points []game.Point) string {
+	vertices := make([]string, 0, len(points))
+	for i := range points {
+		vertices = append(vertices, FormatVertex(&points[i], e.size))
+	}
+	return strings.Join(vertices, " ")
+}
+
+func (e *Engine) fixedHandicap(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", ErrSyntax
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", ErrSyntax
+	}
+	if !e.isEmpty() {
+		return "", ErrBoardNotEmpty
+	}
+
+	points, err := game.FixedHandicapPoints(e.size, n)
+	if err != nil {
+		return "", ErrHandicap
+	}
+
+	e.placeHandicap(points)
+	return e.formatVertices(points), nil
+}
+
+// placeFreeHandicap uses the fixed star points where possible and lets
+// the AI choose any further stones.
+func (e *Engine) placeFreeHandicap(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", ErrSyntax
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", ErrSyntax
+	}
+	if !e.isEmpty() {
+		return "", ErrBoardNotEmpty
+	}
+	if n < 2 || n >= e.size*e.size {
+		return "", ErrHandicap
+	}
+
+	fixed := n
+	if max := game.MaxFixedHandicap(e.size); fixed > max {
+		fixed = max
+	}
+
+	points := []game.Point{}
+	if fixed >= 2 {
+		points, _ = game.FixedHandicapPoints(e.size, fixed)
+	}
+	for _, p := range points {
+		e.game.Board.SetStone(p, game.Black)
+	}
+
+	ai := e.newAI(game.Black)
+	for len(points) < n {
+		e.game.CurrentTurn = game.Black
+		p := ai.GetMove(e.game)
+		if p == nil {
+			break
+		}
+		e.game.Board.SetStone(*p, game.Black)
+		points = append(points, *p)
+	}
+	if len(points) < 2 {
+		e.reset()
+		return "", ErrHandicap
+	}
+
+	e.reset()
+	e.placeHandicap(points)
+	return e.formatVertices(points), nil
+}
+
+func (e *Engine) setFreeHandicap(args []string) (string, error) {
+	if !e.isEmpty() {
+		return "", ErrBoardNotEmpty
+	}
+	if len(args) < 2 || len(args) >= e.size*e.size {
+		return "", ErrVertexList
+	}
+
+	points := make([]game.Point, 0, len(args))
+	seen := make(map[game.Point]bool)
+	for _, arg := range args {
+		p, pass, err := ParseVertex(arg, e.size)
+		if err != nil || pass || seen[p] {
+			return "", ErrVertexList
+		}
+		seen[p] = true
+		points = append(points, p)
+	}
+
+	e.placeHandicap(points)
+	return "", nil
+}
+
+func (e *Engine) timeSettings(args []string) (string, error) {
+	values, err := parseInts(args, 3)
+	if err != nil {
+		return "", err
+	}
+	e.TimeSettings = TimeSettings{
+		MainTime:      values[0],
+		ByoYomiTime:   values[1],
+		ByoYomiStones: values[2],
+	}
+	return "", nil
+}
+
+func (e *Engine) timeLeft(args []string) (string, error) {
+	if len(args) != 3 {
+		return "", ErrSyntax
+	}
+	color, err := ParseColor(args[0])
+	if err != nil {
+		return "", ErrSyntax
+	}
+	values, err := parseInts(args[1:], 2)
+	if err != nil {
+		return "", err
+	}
+	e.TimeLeft[color] = TimeLeft{Time: values[0], Stones: values[1]}
+	return "", nil
+}
+
+func parseInts(args []string, n int) ([]int, error) {
+	if len(args) != n {
+		return nil, ErrSyntax
+	}
+	values := make([]int, n)
+	for i, arg := range args {
+		value, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, ErrSyntax
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func (e *Engine) analyzeCommands(args []string) (string, error) {
+	return strings.Join([]string{
+		"gfx/Ownership/gosim-ownership",
+		"string/Score Estimate/gosim-score_estimate",
+	}, "\n"), nil
+}
+
+// ownership reports each point's ownership estimate as a GoGui
+// INFLUENCE map, from 1 for Black to -1 for White.
+func (e *Engine) ownership(args []string) (string, error) {
+	ownership := e.estimateOwnership()
+	board := e.game.Board
+
+	entries := []string{}
+	for y := 0; y < board.Height; y++ {
+		for x := 0; x < board.Width; x++ {
+			p := game.Point{X: x, Y: y}
+			if v := ownership.At(p); v != 0 {
+				entries = append(entries, FormatVertex(&p, board.Height)+" "+strconv.FormatFloat(v, 'f', 2, 64))
+			}
+		}
+	}
+
+	return "INFLUENCE " + strings.Join(entries, " "), nil
+}
+
+func (e *Engine) scoreEstimate(args []string) (string, error) {
+	score := e.estimateOwnership().Score(e.game)
+	return fmt.Sprintf("%s (Black %s, White %s)", score.GetResult(),
+		strconv.FormatFloat(score.Black, 'f', -1, 64),
+		strconv.FormatFloat(score.White, 'f', -1, 64)), nil
+}
+
+// estimateOwnership runs playouts from the current position, seeded from
+// Seed if it is set.
+func (e *Engine) estimateOwnership() *game.Ownership {
+	seed := e.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+	return game.EstimateOwnership(context.Background(), e.game, 0, rng)
+}
@@ -0,0 +1,36 @@
+package gtp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/coord"
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+// MaxBoardSize is the largest board GTP vertices can address.
+const MaxBoardSize = len(coord.Columns)
+
+// ParseVertex converts a GTP vertex such as "D4" or "pass" into a point
+// on a board of the given height. pass is true for "pass".
+func ParseVertex(vertex string, size int) (p game.Point, pass bool, err error) {
+	c, pass, err := coord.Size{Width: size, Height: size}.ParseVertex(vertex)
+	return game.Point(c), pass, err
+}
+
+// FormatVertex converts a point on a board of the given height to a GTP
+// vertex. A nil point is a pass.
+func FormatVertex(p *game.Point, size int) string {
+	return coord.Size{Width: size, Height: size}.Vertex((*coord.Point)(p))
+}
+
+// ParseColor converts "b", "black", "w" or "white" into a color.
+func ParseColor(color string) (game.Color, error) {
+	switch strings.ToLower(color) {
+	case "b", "black":
+		return game.Black, nil
+	case "w", "white":
+		return game.White, nil
+	}
+	return game.Empty, fmt.Errorf("invalid color %q", color)
+}
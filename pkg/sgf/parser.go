@@ -0,0 +1,232 @@
+package sgf
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SyntaxError reports malformed SGF input.
+type SyntaxError struct {
+	Offset  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("sgf: %s at offset %d", e.Message, e.Offset)
+}
+
+// Parse reads every game tree from r.
+func Parse(r io.Reader) (Collection, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseString(string(data))
+}
+
+// ParseString reads every game tree from s.
+func ParseString(s string) (Collection, error) {
+	p := &parser{data: s}
+	return p.parseCollection()
+}
+
+type parser struct {
+	data string
+	pos  int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Offset: p.pos, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r', '\v', '\f':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) peek() (byte, bool) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return 0, false
+	}
+	return p.data[p.pos], true
+}
+
+func (p *parser) parseCollection() (Collection, error) {
+	collection := Collection{}
+
+	// Anything before the first game tree, such as a mail header, is
+	// ignored as the specification allows.
+	if start := strings.Index(p.data, "("); start >= 0 {
+		p.pos = start
+	} else {
+		return nil, p.errorf("no game tree found")
+	}
+
+	for {
+		c, ok := p.peek()
+		if !ok {
+			break
+		}
+		if c != '(' {
+			return nil, p.errorf("unexpected %q between game trees", c)
+		}
+
+		root, err := p.parseGameTree(nil)
+		if err != nil {
+			return nil, err
+		}
+		collection = append(collection, root)
+	}
+
+	return collection, nil
+}
+
+// parseGameTree reads "(" Sequence GameTree* ")" and returns the first
+// node of the sequence, attached to parent.
+func (p *parser) parseGameTree(parent *Node) (*Node, error) {
+	p.pos++ // consume '('
+
+	var first, last *Node
+	for {
+		c, ok := p.peek()
+		if !ok {
+			return nil, p.errorf("unterminated game tree")
+		}
+		if c != ';' {
+			break
+		}
+		p.pos++
+
+		node := NewNode(last)
+		if last == nil {
+			first = node
+			if parent != nil {
+				parent.AddChild(node)
+			}
+		}
+		if err := p.parseProperties(node); err != nil {
+			return nil, err
+		}
+		last = node
+	}
+
+	if first == nil {
+		return nil, p.errorf("game tree without nodes")
+	}
+
+	for {
+		c, ok := p.peek()
+		if !ok {
+			return nil, p.errorf("unterminated game tree")
+		}
+		switch c {
+		case '(':
+			if _, err := p.parseGameTree(last); err != nil {
+				return nil, err
+			}
+		case ')':
+			p.pos++
+			return first, nil
+		default:
+			return nil, p.errorf("unexpected %q in game tree", c)
+		}
+	}
+}
+
+func (p *parser) parseProperties(node *Node) error {
+	for {
+		c, ok := p.peek()
+		if !ok || !isLetter(c) {
+			return nil
+		}
+
+		id := p.parseIdent()
+		if id == "" {
+			return p.errorf("property identifier without uppercase letters")
+		}
+
+		values := []string{}
+		for {
+			c, ok := p.peek()
+			if !ok || c != '[' {
+				break
+			}
+			value, err := p.parseValue(composeIDs[id])
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			return p.errorf("property %s without value", id)
+		}
+
+		node.Add(id, values...)
+	}
+}
+
+// parseIdent reads a property identifier. Lowercase letters are dropped so
+// that long FF[3] identifiers such as "AddBlack" read as "AB".
+func (p *parser) parseIdent() string {
+	var id strings.Builder
+	for p.pos < len(p.data) && isLetter(p.data[p.pos]) {
+		if c := p.data[p.pos]; c >= 'A' && c <= 'Z' {
+			id.WriteByte(c)
+		}
+		p.pos++
+	}
+	return id.String()
+}
+
+// parseValue reads "[" ValueText "]", resolving escapes and soft line
+// breaks. Escaped colons are kept if compose is set.
+func (p *parser) parseValue(compose bool) (string, error) {
+	start := p.pos
+	p.pos++ // consume '['
+
+	var value strings.Builder
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch c {
+		case ']':
+			p.pos++
+			return value.String(), nil
+		case '\\':
+			p.pos++
+			if p.pos >= len(p.data) {
+				break
+			}
+			escaped := p.data[p.pos]
+			p.pos++
+			if escaped == '\n' || escaped == '\r' {
+				// A soft line break; swallow the other half of \r\n or \n\r.
+				if p.pos < len(p.data) && (p.data[p.pos] == '\n' || p.data[p.pos] == '\r') && p.data[p.pos] != escaped {
+					p.pos++
+				}
+				continue
+			}
+			if compose && escaped == ':' {
+				value.WriteByte('\\')
+			}
+			value.WriteByte(escaped)
+		default:
+			value.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	p.pos = start
+	return "", p.errorf("unterminated property value")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
@@ -0,0 +1,167 @@
+// Package sgf reads Smart Game Format (FF[4]) collections into node trees.
+// It deals only with SGF syntax; interpreting moves and setup properties
+// is left to the game package.
+package sgf
+
+import "strings"
+
+// Property is a single SGF property with one or more values. Values are
+// stored unescaped, except that compose values keep escaped colons as
+// `\:` so that SplitCompose can tell them from the separator.
+type Property struct {
+	ID     string
+	Values []string
+}
+
+// Node is one node of a game tree. The first child continues the main
+// line; further children are variations.
+type Node struct {
+	Properties []Property
+	Parent     *Node
+	Children   []*Node
+}
+
+// Collection is the list of game tree roots in an SGF file.
+type Collection []*Node
+
+// NewNode creates an empty node and attaches it to parent, if any.
+func NewNode(parent *Node) *Node {
+	n := &Node{}
+	if parent != nil {
+		parent.AddChild(n)
+	}
+	return n
+}
+
+// AddChild appends child as the last variation of n.
+func (n *Node) AddChild(child *Node) {
+	child.Parent = n
+	n.Children = append(n.Children, child)
+}
+
+// Has reports whether n has a property with the given ID.
+func (n *Node) Has(id string) bool {
+	return n.property(id) != nil
+}
+
+// Get returns the first value of the property, or "" if it is absent.
+func (n *Node) Get(id string) string {
+	if prop := n.property(id); prop != nil && len(prop.Values) > 0 {
+		return prop.Values[0]
+	}
+	return ""
+}
+
+// Values returns all values of the property.
+func (n *Node) Values(id string) []string {
+	if prop := n.property(id); prop != nil {
+		return prop.Values
+	}
+	return nil
+}
+
+// Set replaces the values of the property, adding it if needed.
+func (n *Node) Set(id string, values ...string) {
+	if prop := n.property(id); prop != nil {
+		prop.Values = values
+		return
+	}
+	n.Properties = append(n.Properties, Property{ID: id, Values: values})
+}
+
+// Add appends values to the property, adding it if needed.
+func (n *Node) Add(id string, values ...string) {
+	if prop := n.property(id); prop != nil {
+		prop.Values = append(prop.Values, values...)
+		return
+	}
+	n.Properties = append(n.Properties, Property{ID: id, Values: values})
+}
+
+// Delete removes the property from n.
+func (n *Node) Delete(id string) {
+	for i := range n.Properties {
+		if n.Properties[i].ID == id {
+			n.Properties = append(n.Properties[:i], n.Properties[i+1:]...)
+			return
+		}
+	}
+}
+
+func (n *Node) property(id string) *Property {
+	for i := range n.Properties {
+		if n.Properties[i].ID == id {
+			return &n.Properties[i]
+		}
+	}
+	return nil
+}
+
+// Root returns the root node of the tree containing n.
+func (n *Node) Root() *Node {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	return n
+}
+
+// Path returns the nodes from the root down to and including n.
+func (n *Node) Path() []*Node {
+	path := []*Node{}
+	for node := n; node != nil; node = node.Parent {
+		path = append(path, node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// MainLine returns n followed by the first child of each node below it.
+func (n *Node) MainLine() []*Node {
+	line := []*Node{}
+	for node := n; node != nil; {
+		line = append(line, node)
+		if len(node.Children) == 0 {
+			break
+		}
+		node = node.Children[0]
+	}
+	return line
+}
+
+// composeIDs are the FF[4] properties whose values may be composed of
+// two parts.
+var composeIDs = map[string]bool{
+	"AP": true,
+	"AR": true,
+	"FG": true,
+	"LB": true,
+	"LN": true,
+	"SZ": true,
+}
+
+// SplitCompose splits a compose value such as "dd:ff" or "dd:label" at the
+// first unescaped colon. ok is false if the value is not composed.
+func SplitCompose(value string) (first, second string, ok bool) {
+	for i := 0; i < len(value); i++ {
+		if value[i] == ':' && (i == 0 || value[i-1] != '\\') {
+			return unescapeColons(value[:i]), unescapeColons(value[i+1:]), true
+		}
+	}
+	return unescapeColons(value), "", false
+}
+
+// Compose joins first and second into a compose value, escaping the
+// colons within them.
+func Compose(first, second string) string {
+	return escapeColons(first) + ":" + escapeColons(second)
+}
+
+func escapeColons(s string) string {
+	return strings.ReplaceAll(s, ":", `\:`)
+}
+
+func unescapeColons(s string) string {
+	return strings.ReplaceAll(s, `\:`, ":")
+}
@@ -0,0 +1,93 @@
+package sgf
+
+import (
+	"io"
+	"strings"
+)
+
+// Write serializes every game tree in c to w.
+func Write(w io.Writer, c Collection) error {
+	_, err := io.WriteString(w, c.String())
+	return err
+}
+
+// String returns the SGF text of the collection, one game tree per line.
+func (c Collection) String() string {
+	var b strings.Builder
+	for i, root := range c {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		writeTree(&b, root)
+	}
+	return b.String()
+}
+
+// String returns the SGF text of the game tree rooted at n.
+func (n *Node) String() string {
+	var b strings.Builder
+	writeTree(&b, n)
+	return b.String()
+}
+
+// writeTree writes n and its descendants as a parenthesized game tree.
+// Runs of single children are written as one sequence so that long games
+// do not nest.
+func writeTree(b *strings.Builder, n *Node) {
+	b.WriteByte('(')
+	for node := n; ; {
+		writeNode(b, node)
+		if node.Parent == nil {
+			b.WriteByte('\n')
+		}
+		if len(node.Children) != 1 {
+			for _, child := range node.Children {
+				b.WriteByte('\n')
+				writeTree(b, child)
+			}
+			break
+		}
+		node = node.Children[0]
+	}
+	b.WriteByte(')')
+}
+
+func writeNode(b *strings.Builder, n *Node) {
+	b.WriteByte(';')
+	for _, prop := range n.Properties {
+		b.WriteString(prop.ID)
+		for _, value := range prop.Values {
+			b.WriteByte('[')
+			if composeIDs[prop.ID] {
+				b.WriteString(escape(value, true))
+			} else {
+				b.WriteString(Escape(value))
+			}
+			b.WriteByte(']')
+		}
+	}
+}
+
+// Escape quotes the characters that cannot appear literally in a
+// property value.
+func Escape(value string) string {
+	return escape(value, false)
+}
+
+// escape is Escape, leaving alone the escaped colons of compose values if
+// compose is set.
+func escape(value string, compose bool) string {
+	if !strings.ContainsAny(value, `]\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		escapedColon := compose && value[i] == '\\' && i+1 < len(value) && value[i+1] == ':'
+		if (value[i] == ']' || value[i] == '\\') && !escapedColon {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
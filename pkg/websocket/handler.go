@@ -3,18 +3,16 @@ package websocket
 import (
 	"encoding/json"
 	"log"
+	"math"
 	"math/rand"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/Prawal-Sharma/GoSim/pkg/game"
 	"github.com/gorilla/websocket"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-}
-
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true
@@ -22,46 +20,68 @@ var upgrader = websocket.Upgrader{
 }
 
 type Client struct {
-	conn   *websocket.Conn
-	game   *game.Game
-	color  game.Color
-	send   chan []byte
-	hub    *Hub
-	id     string
-	roomID string
+	conn *websocket.Conn
+	send chan []byte
+	hub  *Hub
+	id   string
+
+	// done is closed when the connection is lost. send is never closed,
+	// since rooms may still be writing to it.
+	done chan struct{}
+
+	// room is the room the client last created or joined. Only readPump
+	// uses it; seats are kept by the room itself.
+	room *GameRoom
 }
 
+// DefaultGracePeriod is how long a player who loses the connection has to
+// reconnect before forfeiting.
+const DefaultGracePeriod = time.Minute
+
 type Hub struct {
+	// GracePeriod is the time given to players to reconnect. It applies
+	// to rooms created after it is set.
+	GracePeriod time.Duration
+
 	clients    map[*Client]bool
-	rooms      map[string]*GameRoom
 	register   chan *Client
 	unregister chan *Client
 	broadcast  chan Message
-}
 
-type GameRoom struct {
-	ID      string
-	Game    *game.Game
-	Players map[game.Color]*Client
+	roomsMu sync.RWMutex
+	rooms   map[string]*GameRoom
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
 }
 
 type Message struct {
-	Type    string                 `json:"type"`
-	Data    map[string]interface{} `json:"data"`
-	RoomID  string                 `json:"roomId,omitempty"`
-	PlayerID string                `json:"playerId,omitempty"`
+	Type     string                 `json:"type"`
+	Data     map[string]interface{} `json:"data"`
+	RoomID   string                 `json:"roomId,omitempty"`
+	Seq      int                    `json:"seq,omitempty"`
+	PlayerID string                 `json:"playerId,omitempty"`
 }
 
 func NewHub() *Hub {
 	return &Hub{
-		clients:    make(map[*Client]bool),
-		rooms:      make(map[string]*GameRoom),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		broadcast:  make(chan Message),
+		GracePeriod: DefaultGracePeriod,
+		clients:     make(map[*Client]bool),
+		rooms:       make(map[string]*GameRoom),
+		register:    make(chan *Client),
+		unregister:  make(chan *Client),
+		broadcast:   make(chan Message),
+		rng:         rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
+// SetSeed makes the hub's room and client IDs reproducible.
+func (h *Hub) SetSeed(seed int64) {
+	h.rngMu.Lock()
+	defer h.rngMu.Unlock()
+	h.rng = rand.New(rand.NewSource(seed))
+}
+
 func (h *Hub) Run() {
 	for {
 		select {
@@ -72,42 +92,59 @@ func (h *Hub) Run() {
 		case client := <-h.unregister:
 			if _, ok := h.clients[client]; ok {
 				delete(h.clients, client)
-				close(client.send)
 				log.Printf("Client unregistered: %s", client.id)
 			}
 
 		case message := <-h.broadcast:
-			if message.RoomID != "" {
-				if room, ok := h.rooms[message.RoomID]; ok {
-					for _, player := range room.Players {
-						if player != nil {
-							data, _ := json.Marshal(message)
-							select {
-							case player.send <- data:
-							default:
-								close(player.send)
-								delete(h.clients, player)
-							}
-						}
-					}
-				}
-			} else {
-				data, _ := json.Marshal(message)
-				for client := range h.clients {
-					select {
-					case client.send <- data:
-					default:
-						close(client.send)
-						delete(h.clients, client)
-					}
-				}
+			// Rooms send to their own players; this reaches everyone.
+			data, _ := json.Marshal(message)
+			for client := range h.clients {
+				client.deliver(data)
 			}
 		}
 	}
 }
 
+// room returns the room with id, if it is still open.
+func (h *Hub) room(id string) (*GameRoom, bool) {
+	h.roomsMu.RLock()
+	defer h.roomsMu.RUnlock()
+	room, ok := h.rooms[id]
+	return room, ok
+}
+
+// addRoom gives room an unused ID and makes it available to join.
+func (h *Hub) addRoom(room *GameRoom) {
+	h.roomsMu.Lock()
+	defer h.roomsMu.Unlock()
+	for {
+		room.ID = h.generateID()
+		if _, taken := h.rooms[room.ID]; !taken {
+			break
+		}
+	}
+	h.rooms[room.ID] = room
+}
+
+func (h *Hub) removeRoom(room *GameRoom) {
+	h.roomsMu.Lock()
+	defer h.roomsMu.Unlock()
+	delete(h.rooms, room.ID)
+}
+
+// RoomCount returns the number of open rooms.
+func (h *Hub) RoomCount() int {
+	h.roomsMu.RLock()
+	defer h.roomsMu.RUnlock()
+	return len(h.rooms)
+}
+
 func (c *Client) readPump() {
 	defer func() {
+		close(c.done)
+		if c.room != nil {
+			c.room.leave(c)
+		}
 		c.hub.unregister <- c
 		c.conn.Close()
 	}()
@@ -131,13 +168,12 @@ func (c *Client) writePump() {
 
 	for {
 		select {
-		case message, ok := <-c.send:
-			if !ok {
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
-
+		case message := <-c.send:
 			c.conn.WriteMessage(websocket.TextMessage, message)
+
+		case <-c.done:
+			c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+			return
 		}
 	}
 }
@@ -146,251 +182,244 @@ func (c *Client) handleMessage(msg Message) {
 	switch msg.Type {
 	case "create_game":
 		c.handleCreateGame(msg)
-	case "join_game":
+	case "join_game", "reconnect":
 		c.handleJoinGame(msg)
-	case "make_move":
-		c.handleMakeMove(msg)
-	case "pass":
-		c.handlePass(msg)
-	case "resign":
-		c.handleResign(msg)
-	case "undo":
-		c.handleUndo(msg)
-	case "get_valid_moves":
-		c.handleGetValidMoves(msg)
+	default:
+		// Everything else acts on the game, so it goes to the room's
+		// goroutine.
+		if c.room == nil || !c.room.submit(c, msg) {
+			c.sendError("Not in a game")
+		}
 	}
 }
 
-func (c *Client) handleCreateGame(msg Message) {
-	boardSize := 19
-	if size, ok := msg.Data["boardSize"].(float64); ok {
-		boardSize = int(size)
-	}
-
-	roomID := generateRoomID()
-	gameRoom := &GameRoom{
-		ID:      roomID,
-		Game:    game.NewGame(boardSize),
-		Players: make(map[game.Color]*Client),
+// enterRoom makes room the one the client's messages go to, leaving the
+// one it was in.
+func (c *Client) enterRoom(room *GameRoom) {
+	if c.room != nil && c.room != room {
+		c.room.leave(c)
 	}
-
-	gameRoom.Players[game.Black] = c
-	c.game = gameRoom.Game
-	c.color = game.Black
-	c.roomID = roomID
-
-	c.hub.rooms[roomID] = gameRoom
-
-	response := Message{
-		Type: "game_created",
-		Data: map[string]interface{}{
-			"roomId":    roomID,
-			"boardSize": boardSize,
-			"color":     "Black",
-		},
-	}
-
-	data, _ := json.Marshal(response)
-	c.send <- data
+	c.room = room
 }
 
-func (c *Client) handleJoinGame(msg Message) {
-	roomID, ok := msg.Data["roomId"].(string)
-	if !ok {
-		c.sendError("Invalid room ID")
-		return
-	}
-
-	room, exists := c.hub.rooms[roomID]
-	if !exists {
-		c.sendError("Room not found")
+func (c *Client) handleCreateGame(msg Message) {
+	width, height, err := boardDimensions(msg.Data)
+	if err != nil {
+		c.sendError(err.Error())
 		return
 	}
-
-	if room.Players[game.White] != nil {
-		c.sendError("Game is full")
+	newGame, err := game.NewRectangularGame(width, height)
+	if err != nil {
+		c.sendError(err.Error())
 		return
 	}
 
-	room.Players[game.White] = c
-	c.game = room.Game
-	c.color = game.White
-	c.roomID = roomID
+	gameRoom := newGameRoom(c.hub, newGame)
 
-	response := Message{
-		Type: "game_joined",
-		Data: map[string]interface{}{
-			"roomId":    roomID,
-			"boardSize": room.Game.Board.Size,
-			"color":     "White",
-		},
+	// "rules" names a preset ruleset, which also sets the default komi.
+	if rules, ok := msg.Data["rules"].(string); ok {
+		if err := gameRoom.Game.SetRuleset(rules); err != nil {
+			c.sendError(err.Error())
+			return
+		}
 	}
 
-	data, _ := json.Marshal(response)
-	c.send <- data
-
-	c.hub.broadcast <- Message{
-		Type:   "game_started",
-		RoomID: roomID,
-		Data: map[string]interface{}{
-			"board": c.game.GetBoardState(),
-			"info":  c.game.GetGameInfo(),
-		},
+	// A handicap goes on the star points, unless handicapPlacement is
+	// "free", in which case Black places it with place_handicap.
+	handicap := 0
+	if n, ok := msg.Data["handicap"].(float64); ok && n >= 2 {
+		handicap = int(n)
+		if placement, _ := msg.Data["handicapPlacement"].(string); placement == "free" {
+			if handicap >= width*height {
+				c.sendError(game.ErrInvalidHandicap.Error())
+				return
+			}
+			gameRoom.PendingHandicap = handicap
+			gameRoom.Game.Rules.Komi = game.HandicapKomi
+		} else if err := gameRoom.Game.SetFixedHandicap(handicap); err != nil {
+			c.sendError(err.Error())
+			return
+		}
 	}
-}
 
-func (c *Client) handleMakeMove(msg Message) {
-	if c.game == nil {
-		c.sendError("Not in a game")
-		return
+	// An explicit komi overrides the handicap default.
+	if komi, ok := msg.Data["komi"].(float64); ok {
+		gameRoom.Game.Rules.Komi = komi
 	}
 
-	x, okX := msg.Data["x"].(float64)
-	y, okY := msg.Data["y"].(float64)
-
-	if !okX || !okY {
-		c.sendError("Invalid move coordinates")
-		return
+	// An "engine" seats a registered engine as White instead of waiting
+	// for a second player.
+	engineName, _ := msg.Data["engine"].(string)
+	if engineName != "" {
+		engine, err := game.NewEngine(engineName)
+		if err != nil {
+			c.sendError(err.Error())
+			return
+		}
+		gameRoom.Engines[game.White] = engine
+		if seed, ok := msg.Data["seed"].(float64); ok {
+			if seeder, ok := engine.(game.Seeder); ok {
+				seeder.SetSeed(int64(seed))
+			}
+		}
+		if margin, ok := msg.Data["resignMargin"].(float64); ok {
+			if ai, ok := engine.(*game.AI); ok {
+				ai.ResignMargin = margin
+			}
+		}
+		if moveTime, ok := msg.Data["moveTime"].(float64); ok {
+			gameRoom.EngineTime = time.Duration(moveTime) * time.Millisecond
+		}
+		// Without a moveTime MCTS would think for all of maxEngineTime,
+		// so it gets a playout budget instead.
+		if ai, ok := engine.(*game.AI); ok && gameRoom.EngineTime <= 0 {
+			ai.Playouts = game.DefaultPlayouts
+		}
 	}
 
-	point := game.Point{X: int(x), Y: int(y)}
+	// Two players agree on dead stones after passing; engines cannot, so
+	// games against them are scored as soon as play ends.
+	gameRoom.Game.AgreeDeadStones = engineName == ""
 
-	err := c.game.MakeMove(point, c.color)
-	if err != nil {
-		c.sendError(err.Error())
-		return
-	}
+	token := gameRoom.takeSeat(game.Black, c)
+	c.hub.addRoom(gameRoom)
+	c.enterRoom(gameRoom)
 
-	c.hub.broadcast <- Message{
-		Type:   "move_made",
-		RoomID: c.roomID,
+	response := Message{
+		Type: "game_created",
 		Data: map[string]interface{}{
-			"x":     int(x),
-			"y":     int(y),
-			"color": c.color.String(),
-			"board": c.game.GetBoardState(),
-			"info":  c.game.GetGameInfo(),
+			"roomId":      gameRoom.ID,
+			"boardSize":   width,
+			"boardWidth":  width,
+			"boardHeight": height,
+			"color":       "Black",
+			"token":       token,
+			"handicap":    handicap,
+			"komi":        gameRoom.Game.Rules.Komi,
+			"rules":       gameRoom.Game.Rules.ScoringMethod,
 		},
 	}
-}
-
-func (c *Client) handlePass(msg Message) {
-	if c.game == nil {
-		c.sendError("Not in a game")
-		return
+	if gameRoom.Game.Rules.Name != "" {
+		response.Data["ruleset"] = gameRoom.Game.Rules.Name
 	}
-
-	err := c.game.Pass(c.color)
-	if err != nil {
-		c.sendError(err.Error())
-		return
+	if engineName != "" {
+		response.Data["opponent"] = engineName
+		if seeder, ok := gameRoom.Engines[game.White].(game.Seeder); ok {
+			response.Data["seed"] = seeder.Seed()
+		}
 	}
 
-	c.hub.broadcast <- Message{
-		Type:   "pass",
-		RoomID: c.roomID,
-		Data: map[string]interface{}{
-			"color": c.color.String(),
-			"info":  c.game.GetGameInfo(),
-		},
-	}
+	c.sendMessage(response)
 
-	if c.game.IsOver {
-		c.hub.broadcast <- Message{
-			Type:   "game_over",
-			RoomID: c.roomID,
+	// The room is the client's alone until run starts, so it can still be
+	// used from here.
+	if engineName != "" {
+		gameRoom.broadcast(Message{
+			Type: "game_started",
 			Data: map[string]interface{}{
-				"winner": c.game.Winner,
-				"scores": c.game.CalculateScore(),
+				"board": gameRoom.Game.GetBoardState(),
+				"info":  gameRoom.Game.GetGameInfo(),
 			},
-		}
+		})
 	}
+	go gameRoom.run()
 }
 
-func (c *Client) handleResign(msg Message) {
-	if c.game == nil {
-		c.sendError("Not in a game")
+func (c *Client) handleJoinGame(msg Message) {
+	roomID, ok := msg.Data["roomId"].(string)
+	if !ok {
+		c.sendError("Invalid room ID")
 		return
 	}
 
-	c.game.Resign(c.color)
-
-	c.hub.broadcast <- Message{
-		Type:   "resign",
-		RoomID: c.roomID,
-		Data: map[string]interface{}{
-			"color":  c.color.String(),
-			"winner": game.OpponentColor(c.color).String(),
-		},
-	}
-}
-
-func (c *Client) handleUndo(msg Message) {
-	if c.game == nil {
-		c.sendError("Not in a game")
+	room, exists := c.hub.room(roomID)
+	if !exists {
+		c.sendError("Room not found")
 		return
 	}
 
-	success := c.game.Undo()
-	if !success {
-		c.sendError("Cannot undo")
+	// The room decides whether there is a seat free, or whether the
+	// token given to reconnect is one of its own. The client keeps its
+	// current room, and seat, unless it gets one.
+	seated, open := room.join(c, msg)
+	if !open {
+		c.sendError("Room not found")
 		return
 	}
-
-	c.hub.broadcast <- Message{
-		Type:   "undo",
-		RoomID: c.roomID,
-		Data: map[string]interface{}{
-			"board": c.game.GetBoardState(),
-			"info":  c.game.GetGameInfo(),
-		},
+	if seated {
+		c.enterRoom(room)
 	}
 }
 
-func (c *Client) handleGetValidMoves(msg Message) {
-	if c.game == nil {
-		c.sendError("Not in a game")
-		return
-	}
-
-	validMoves := c.game.GetValidMoves(c.color)
-	movesData := []map[string]int{}
-
-	for _, move := range validMoves {
-		movesData = append(movesData, map[string]int{
-			"x": move.X,
-			"y": move.Y,
-		})
-	}
-
-	response := Message{
-		Type: "valid_moves",
-		Data: map[string]interface{}{
-			"moves": movesData,
-		},
+// boardDimensions reads a square boardSize, or boardWidth and boardHeight
+// for a rectangular board, from create_game data. The default is 19x19.
+func boardDimensions(data map[string]interface{}) (int, int, error) {
+	width, height := 19, 19
+	fields := []struct {
+		key     string
+		targets []*int
+	}{
+		{"boardSize", []*int{&width, &height}},
+		{"boardWidth", []*int{&width}},
+		{"boardHeight", []*int{&height}},
+	}
+	for _, field := range fields {
+		value, ok := data[field.key]
+		if !ok {
+			continue
+		}
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) || n < game.MinBoardSize || n > game.MaxBoardSize {
+			return 0, 0, game.ErrInvalidBoardSize
+		}
+		for _, target := range field.targets {
+			*target = int(n)
+		}
 	}
-
-	data, _ := json.Marshal(response)
-	c.send <- data
+	return width, height, nil
 }
 
 func (c *Client) sendError(errorMsg string) {
-	response := Message{
+	c.sendMessage(Message{
 		Type: "error",
 		Data: map[string]interface{}{
 			"message": errorMsg,
 		},
+	})
+}
+
+func (c *Client) sendMessage(msg Message) {
+	data, _ := json.Marshal(msg)
+	c.deliver(data)
+}
+
+// deliver queues data for writePump. A client too slow to keep up with
+// its messages is disconnected rather than allowed to hold up its room.
+func (c *Client) deliver(data []byte) {
+	select {
+	case c.send <- data:
+	case <-c.done:
+	default:
+		c.conn.Close()
 	}
+}
 
-	data, _ := json.Marshal(response)
-	c.send <- data
+// newRand returns a generator seeded from the hub's, for work such as
+// playouts that runs outside the hub's lock.
+func (h *Hub) newRand() *rand.Rand {
+	h.rngMu.Lock()
+	defer h.rngMu.Unlock()
+	return rand.New(rand.NewSource(h.rng.Int63()))
 }
 
-func generateRoomID() string {
+func (h *Hub) generateID() string {
+	h.rngMu.Lock()
+	defer h.rngMu.Unlock()
+
 	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	roomID := ""
 	for i := 0; i < 6; i++ {
-		roomID += string(letters[rand.Intn(len(letters))])
+		roomID += string(letters[h.rng.Intn(len(letters))])
 	}
 	return roomID
 }
@@ -406,11 +435,12 @@ func HandleWebSocket(hub *Hub, w http.ResponseWriter, r *http.Request) {
 		hub:  hub,
 		conn: conn,
 		send: make(chan []byte, 256),
-		id:   generateRoomID(),
+		done: make(chan struct{}),
+		id:   hub.generateID(),
 	}
 
 	client.hub.register <- client
 
 	go client.writePump()
 	go client.readPump()
-}
\ No newline at end of file
+}
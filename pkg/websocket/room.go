@@ -0,0 +1,830 @@
+package websocket
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+// maxEngineTime is the most an engine may think about a single move.
+const maxEngineTime = 30 * time.Second
+
+// maxEvents is the number of broadcasts a room keeps for players who
+// reconnect. One who missed more is sent only the current state.
+const maxEvents = 256
+
+// GameRoom is a game and the clients and engines playing it. Once run is
+// started, its fields belong to run's goroutine: clients reach the room
+// only through submit and leave, so moves are applied one at a time.
+// Engines search a copy of the game on goroutines of their own and post
+// their decisions back to run.
+type GameRoom struct {
+	ID      string
+	Game    *game.Game
+	Players map[game.Color]*Client
+
+	// Engines holds the seats taken by engines from the game package's
+	// registry, and EngineTime is the budget each of their moves gets.
+	Engines    map[game.Color]game.Engine
+	EngineTime time.Duration
+
+	// PendingHandicap is the number of stones Black still has to place
+	// with place_handicap in a free handicap game.
+	PendingHandicap int
+
+	// tokens holds each seat's session token, with which a player whose
+	// connection drops can reclaim the seat. away holds the grace timers
+	// of the players who are gone.
+	tokens      map[game.Color]string
+	away        map[game.Color]*absence
+	gracePeriod time.Duration
+
+	// events holds the last maxEvents messages broadcast, for players who
+	// reconnect, and seq is the Seq of the last one.
+	events [][]byte
+	seq    int
+
+	// thinking cancels the engine search in progress, or is nil. The
+	// search's result arrives on decisions.
+	thinking  context.CancelFunc
+	decisions chan engineDecision
+
+	// scoringRound counts the times scoring has started. The dead stones
+	// suggested for the latest arrive on suggestions; estimating cancels
+	// the estimate behind them.
+	scoringRound int
+	estimating   context.CancelFunc
+	suggestions  chan deadStoneSuggestion
+
+	hub      *Hub
+	commands chan roomCommand
+	leaving  chan *Client
+	expired  chan *absence
+	done     chan struct{}
+}
+
+// deadStoneSuggestion is the ownership estimate made when scoring started
+// for the round'th time.
+type deadStoneSuggestion struct {
+	round     int
+	ownership *game.Ownership
+}
+
+// engineDecision is what an engine chose to do as color at node.
+// err is why the engine could not choose a move, if it failed.
+type engineDecision struct {
+	node   *game.MoveNode
+	color  game.Color
+	move   *game.Point
+	resign bool
+	err    error
+}
+
+// absence is a player's time away from the room.
+type absence struct {
+	color game.Color
+	timer *time.Timer
+}
+
+// roomCommand is a message from a client for its room's goroutine. A
+// join_game or reconnect command is answered on seated.
+type roomCommand struct {
+	client *Client
+	msg    Message
+	seated chan bool
+}
+
+func newGameRoom(hub *Hub, g *game.Game) *GameRoom {
+	return &GameRoom{
+		Game:        g,
+		Players:     make(map[game.Color]*Client),
+		Engines:     make(map[game.Color]game.Engine),
+		tokens:      make(map[game.Color]string),
+		away:        make(map[game.Color]*absence),
+		gracePeriod: hub.GracePeriod,
+		decisions:   make(chan engineDecision),
+		suggestions: make(chan deadStoneSuggestion),
+		hub:         hub,
+		commands:    make(chan roomCommand, 16),
+		leaving:     make(chan *Client),
+		expired:     make(chan *absence),
+		done:        make(chan struct{}),
+	}
+}
+
+// run handles the room's commands until no player is left or due back,
+// then removes it from the hub. Engines due to move when it starts, such
+// as White after a fixed handicap, move first.
+func (r *GameRoom) run() {
+	defer close(r.done)
+	defer r.hub.removeRoom(r)
+
+	r.startEngine()
+	for len(r.Players) > 0 || len(r.away) > 0 {
+		select {
+		case cmd := <-r.commands:
+			if cmd.seated != nil {
+				cmd.seated <- r.handleSeat(cmd.client, cmd.msg)
+			} else {
+				r.handle(cmd.client, cmd.msg)
+			}
+
+		case client := <-r.leaving:
+			r.handleLeave(client)
+
+		case a := <-r.expired:
+			r.handleExpiry(a)
+
+		case decision := <-r.decisions:
+			r.handleDecision(decision)
+
+		case suggestion := <-r.suggestions:
+			r.handleSuggestion(suggestion)
+		}
+	}
+	if !r.Game.IsOver {
+		r.closeEngines()
+	}
+}
+
+// submit passes msg from client to the room's goroutine. It reports false
+// if the room has already closed.
+func (r *GameRoom) submit(client *Client, msg Message) bool {
+	select {
+	case r.commands <- roomCommand{client: client, msg: msg}:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+// join passes a join_game or reconnect message from client to the room's
+// goroutine and waits for its answer. It reports whether client was given
+// a seat, and false for open if the room has already closed.
+func (r *GameRoom) join(client *Client, msg Message) (seated, open bool) {
+	cmd := roomCommand{client: client, msg: msg, seated: make(chan bool, 1)}
+	select {
+	case r.commands <- cmd:
+	case <-r.done:
+		return false, false
+	}
+	select {
+	case seated = <-cmd.seated:
+		return seated, true
+	case <-r.done:
+		return false, false
+	}
+}
+
+// leave gives up client's seat, if it has one.
+func (r *GameRoom) leave(client *Client) {
+	select {
+	case r.leaving <- client:
+	case <-r.done:
+	}
+}
+
+// takeSeat seats client as color and returns the seat's session token.
+func (r *GameRoom) takeSeat(color game.Color, client *Client) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	r.Players[color] = client
+	r.tokens[color] = token
+	return token
+}
+
+// seat returns the color client plays in the room.
+func (r *GameRoom) seat(client *Client) (game.Color, bool) {
+	for color, player := range r.Players {
+		if player == client {
+			return color, true
+		}
+	}
+	return game.Empty, false
+}
+
+// handleSeat handles a join_game or reconnect message, reporting whether
+// c was given a seat.
+func (r *GameRoom) handleSeat(c *Client, msg Message) bool {
+	if msg.Type == "reconnect" {
+		return r.handleReconnect(c, msg)
+	}
+	return r.handleJoin(c)
+}
+
+func (r *GameRoom) handle(c *Client, msg Message) {
+	color, ok := r.seat(c)
+	if !ok {
+		c.sendError("Not in a game")
+		return
+	}
+
+	switch msg.Type {
+	case "make_move":
+		r.handleMakeMove(c, color, msg)
+	case "pass":
+		r.handlePass(c, color)
+	case "resign":
+		r.Game.Resign(color)
+		r.broadcastResign(color)
+	case "undo":
+		r.handleUndo(c)
+	case "redo":
+		r.handleRedo(c)
+	case "get_valid_moves":
+		r.handleGetValidMoves(c, color)
+	case "toggle_dead":
+		r.handleToggleDead(c, msg)
+	case "accept_score":
+		r.handleAcceptScore(c, color)
+	case "resume_play":
+		r.handleResumePlay(c, color)
+	case "place_handicap":
+		r.handlePlaceHandicap(c, color, msg)
+	}
+}
+
+// broadcast sends msg to every player in the room, numbering it and
+// keeping it for players who are away.
+func (r *GameRoom) broadcast(msg Message) {
+	msg.RoomID = r.ID
+	r.seq++
+	msg.Seq = r.seq
+	data, _ := json.Marshal(msg)
+	if len(r.events) == maxEvents {
+		r.events = r.events[1:]
+	}
+	r.events = append(r.events, data)
+	for _, player := range r.Players {
+		player.deliver(data)
+	}
+}
+
+func (r *GameRoom) handleJoin(c *Client) bool {
+	if _, seated := r.seat(c); seated || r.tokens[game.White] != "" || r.Engines[game.White] != nil {
+		c.sendError("Game is full")
+		return false
+	}
+	token := r.takeSeat(game.White, c)
+
+	c.sendMessage(Message{
+		Type: "game_joined",
+		Data: map[string]interface{}{
+			"roomId":      r.ID,
+			"boardSize":   r.Game.Board.Width,
+			"boardWidth":  r.Game.Board.Width,
+			"boardHeight": r.Game.Board.Height,
+			"color":       "White",
+			"token":       token,
+		},
+	})
+
+	r.broadcast(Message{
+		Type: "game_started",
+		Data: map[string]interface{}{
+			"board": r.Game.GetBoardState(),
+			"info":  r.Game.GetGameInfo(),
+		},
+	})
+	return true
+}
+
+// handleLeave keeps client's seat for the grace period, after which the
+// player forfeits unless they have reconnected.
+func (r *GameRoom) handleLeave(client *Client) {
+	color, ok := r.seat(client)
+	if !ok {
+		return
+	}
+	delete(r.Players, color)
+
+	a := &absence{color: color}
+	a.timer = time.AfterFunc(r.gracePeriod, func() {
+		select {
+		case r.expired <- a:
+		case <-r.done:
+		}
+	})
+	r.away[color] = a
+
+	r.broadcast(Message{
+		Type: "player_left",
+		Data: map[string]interface{}{
+			"color":       color.String(),
+			"gracePeriod": r.gracePeriod.Milliseconds(),
+		},
+	})
+}
+
+// handleExpiry ends the game in the opponent's favour if a's player is
+// still away.
+func (r *GameRoom) handleExpiry(a *absence) {
+	if r.away[a.color] != a {
+		return
+	}
+	delete(r.away, a.color)
+	if r.Game.IsOver {
+		return
+	}
+
+	r.Game.Resign(a.color)
+	r.broadcast(Message{
+		Type: "forfeit",
+		Data: map[string]interface{}{
+			"color":  a.color.String(),
+			"winner": game.OpponentColor(a.color).String(),
+		},
+	})
+	r.closeEngines()
+}
+
+// handleReconnect gives the seat whose token c presents to c, replacing
+// any connection still holding it, which is told so. c is sent the
+// current state and the messages broadcast since lastSeq.
+func (r *GameRoom) handleReconnect(c *Client, msg Message) bool {
+	token, _ := msg.Data["token"].(string)
+	color := game.Empty
+	for seatColor, seatToken := range r.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(seatToken)) == 1 {
+			color = seatColor
+		}
+	}
+	if color == game.Empty {
+		c.sendError("Invalid session")
+		return false
+	}
+	if seated, ok := r.seat(c); ok && seated != color {
+		c.sendError("Already playing " + seated.String())
+		return false
+	}
+
+	if a, ok := r.away[color]; ok {
+		a.timer.Stop()
+		delete(r.away, color)
+	}
+	if old := r.Players[color]; old != nil && old != c {
+		old.sendMessage(Message{
+			Type:   "replaced",
+			RoomID: r.ID,
+			Data: map[string]interface{}{
+				"color": color.String(),
+			},
+		})
+	}
+	r.Players[color] = c
+
+	// If some of the messages since lastSeq are no longer kept, the
+	// player makes do with the current state.
+	missed := []json.RawMessage{}
+	lastSeq, _ := msg.Data["lastSeq"].(float64)
+	oldest := r.seq - len(r.events)
+	resync := int(lastSeq) < oldest || int(lastSeq) > r.seq
+	if !resync {
+		for _, data := range r.events[int(lastSeq)-oldest:] {
+			missed = append(missed, data)
+		}
+	}
+	c.sendMessage(Message{
+		Type:   "reconnected",
+		RoomID: r.ID,
+		Data: map[string]interface{}{
+			"roomId":          r.ID,
+			"color":           color.String(),
+			"boardWidth":      r.Game.Board.Width,
+			"boardHeight":     r.Game.Board.Height,
+			"board":           r.Game.GetBoardState(),
+			"info":            r.Game.GetGameInfo(),
+			"pendingHandicap": r.PendingHandicap,
+			"seq":             r.seq,
+			"missed":          missed,
+			"resync":          resync,
+		},
+	})
+
+	r.broadcast(Message{
+		Type: "player_returned",
+		Data: map[string]interface{}{
+			"color": color.String(),
+		},
+	})
+	return true
+}
+
+// handlePlaceHandicap places Black's stones in a free handicap game.
+func (r *GameRoom) handlePlaceHandicap(c *Client, color game.Color, msg Message) {
+	if color != game.Black || r.PendingHandicap == 0 {
+		c.sendError("No handicap to place")
+		return
+	}
+
+	stones, _ := msg.Data["stones"].([]interface{})
+	if len(stones) != r.PendingHandicap {
+		c.sendError(game.ErrInvalidHandicap.Error())
+		return
+	}
+
+	points := make([]game.Point, 0, len(stones))
+	for _, stone := range stones {
+		coords, _ := stone.(map[string]interface{})
+		x, okX := coords["x"].(float64)
+		y, okY := coords["y"].(float64)
+		if !okX || !okY {
+			c.sendError("Invalid handicap coordinates")
+			return
+		}
+		points = append(points, game.Point{X: int(x), Y: int(y)})
+	}
+
+	// Komi was settled when the room was created.
+	komi := r.Game.Rules.Komi
+	if err := r.Game.PlaceHandicap(points); err != nil {
+		c.sendError(err.Error())
+		return
+	}
+	r.Game.Rules.Komi = komi
+	r.PendingHandicap = 0
+
+	r.broadcast(Message{
+		Type: "handicap_placed",
+		Data: map[string]interface{}{
+			"board": r.Game.GetBoardState(),
+			"info":  r.Game.GetGameInfo(),
+		},
+	})
+	r.startEngine()
+}
+
+// waitingForHandicap rejects moves until a free handicap is placed.
+func (r *GameRoom) waitingForHandicap(c *Client) bool {
+	if r.PendingHandicap > 0 {
+		c.sendError("Place the handicap stones first")
+		return true
+	}
+	return false
+}
+
+func (r *GameRoom) handleMakeMove(c *Client, color game.Color, msg Message) {
+	x, okX := msg.Data["x"].(float64)
+	y, okY := msg.Data["y"].(float64)
+
+	if !okX || !okY {
+		c.sendError("Invalid move coordinates")
+		return
+	}
+
+	point := game.Point{X: int(x), Y: int(y)}
+	if r.waitingForHandicap(c) {
+		return
+	}
+
+	if err := r.Game.MakeMove(point, color); err != nil {
+		c.sendError(r.pointError(point, err))
+		return
+	}
+
+	r.broadcastMove(point, color)
+	r.startEngine()
+}
+
+func (r *GameRoom) broadcastMove(point game.Point, color game.Color) {
+	r.broadcast(Message{
+		Type: "move_made",
+		Data: map[string]interface{}{
+			"x":      point.X,
+			"y":      point.Y,
+			"vertex": r.Game.Board.Label(point),
+			"color":  color.String(),
+			"board":  r.Game.GetBoardState(),
+			"info":   r.Game.GetGameInfo(),
+		},
+	})
+}
+
+// pointError describes err for an action at point, naming the point when
+// it is on the board.
+func (r *GameRoom) pointError(point game.Point, err error) string {
+	if r.Game.Board.IsValidPoint(point) {
+		return r.Game.Board.Label(point) + ": " + err.Error()
+	}
+	return err.Error()
+}
+
+func (r *GameRoom) handlePass(c *Client, color game.Color) {
+	if r.waitingForHandicap(c) {
+		return
+	}
+
+	if err := r.Game.Pass(color); err != nil {
+		c.sendError(err.Error())
+		return
+	}
+
+	r.broadcastPass(color)
+	r.startEngine()
+}
+
+func (r *GameRoom) broadcastPass(color game.Color) {
+	r.broadcast(Message{
+		Type: "pass",
+		Data: map[string]interface{}{
+			"color": color.String(),
+			"info":  r.Game.GetGameInfo(),
+		},
+	})
+
+	if r.Game.Scoring {
+		r.suggestDeadStones()
+	} else if r.Game.IsOver {
+		r.broadcastGameOver()
+	}
+}
+
+// suggestDeadStones estimates ownership off the room's goroutine, as an
+// engine search is, within maxEngineTime. Scoring is announced, starting
+// from the stones the estimate thinks are dead, once it arrives.
+func (r *GameRoom) suggestDeadStones() {
+	if r.estimating != nil {
+		r.estimating()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), maxEngineTime)
+	r.estimating = cancel
+	r.scoringRound++
+
+	suggestion := deadStoneSuggestion{round: r.scoringRound}
+	position := r.Game.Clone()
+	rng := r.hub.newRand()
+	go func() {
+		defer cancel()
+		suggestion.ownership = game.EstimateOwnership(ctx, position, 0, rng)
+		select {
+		case r.suggestions <- suggestion:
+		case <-r.done:
+		}
+	}()
+}
+
+// handleSuggestion marks the suggested dead stones and announces scoring,
+// unless play has resumed or been undone since the estimate started.
+func (r *GameRoom) handleSuggestion(s deadStoneSuggestion) {
+	if s.round != r.scoringRound || !r.Game.Scoring {
+		return
+	}
+	r.estimating = nil
+	r.Game.SuggestDeadStones(s.ownership)
+	r.broadcastScoring("scoring_started")
+}
+
+func (r *GameRoom) broadcastGameOver() {
+	score := r.Game.Score()
+	data := map[string]interface{}{
+		"scores": score.Summary(),
+		"result": score.GetResult(),
+	}
+	if score.Winner != nil {
+		data["winner"] = score.Winner.String()
+	}
+
+	r.broadcast(Message{
+		Type: "game_over",
+		Data: data,
+	})
+	r.closeEngines()
+}
+
+// broadcastScoring sends the dead stones as currently marked and the
+// provisional score they give.
+func (r *GameRoom) broadcastScoring(messageType string) {
+	deadStones := []map[string]int{}
+	for _, p := range r.Game.DeadStoneList() {
+		deadStones = append(deadStones, map[string]int{"x": p.X, "y": p.Y})
+	}
+	result := game.GetGameResult(r.Game, r.Game.Rules.ScoringMethod, r.Game.Rules.Komi)
+
+	r.broadcast(Message{
+		Type: messageType,
+		Data: map[string]interface{}{
+			"deadStones": deadStones,
+			"scores":     result.Score.Summary(),
+			"accepted": map[string]bool{
+				"Black": r.Game.Accepted[game.Black],
+				"White": r.Game.Accepted[game.White],
+			},
+		},
+	})
+}
+
+func (r *GameRoom) handleToggleDead(c *Client, msg Message) {
+	x, okX := msg.Data["x"].(float64)
+	y, okY := msg.Data["y"].(float64)
+	if !okX || !okY {
+		c.sendError("Invalid coordinates")
+		return
+	}
+
+	point := game.Point{X: int(x), Y: int(y)}
+	if err := r.Game.ToggleDead(point); err != nil {
+		c.sendError(r.pointError(point, err))
+		return
+	}
+	r.broadcastScoring("scoring_updated")
+}
+
+func (r *GameRoom) handleAcceptScore(c *Client, color game.Color) {
+	if err := r.Game.AcceptScore(color); err != nil {
+		c.sendError(err.Error())
+		return
+	}
+
+	if r.Game.IsOver {
+		r.broadcastGameOver()
+	} else {
+		r.broadcastScoring("scoring_updated")
+	}
+}
+
+func (r *GameRoom) handleResumePlay(c *Client, color game.Color) {
+	if err := r.Game.ResumePlay(color); err != nil {
+		c.sendError(err.Error())
+		return
+	}
+
+	r.broadcast(Message{
+		Type: "play_resumed",
+		Data: map[string]interface{}{
+			"color": color.String(),
+			"board": r.Game.GetBoardState(),
+			"info":  r.Game.GetGameInfo(),
+		},
+	})
+}
+
+// startEngine starts the search of the engine due to move, if any and
+// unless one is already searching. The search is limited to EngineTime,
+// or to maxEngineTime if EngineTime is unset or larger.
+func (r *GameRoom) startEngine() {
+	if r.thinking != nil || r.PendingHandicap > 0 || r.Game.IsOver || r.Game.Scoring {
+		return
+	}
+	color := r.Game.CurrentTurn
+	engine, ok := r.Engines[color]
+	if !ok {
+		return
+	}
+
+	budget := r.EngineTime
+	if budget <= 0 || budget > maxEngineTime {
+		budget = maxEngineTime
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	r.thinking = cancel
+
+	decision := engineDecision{node: r.Game.Current, color: color}
+	position := r.Game.Clone()
+	go func() {
+		defer cancel()
+		if resigner, ok := engine.(game.Resigner); ok && resigner.ShouldResign(ctx, position, color) {
+			decision.resign = true
+		} else if fallible, ok := engine.(game.FallibleEngine); ok {
+			decision.move, decision.err = fallible.TryGenerateMove(ctx, position, color)
+			if errors.Is(decision.err, game.ErrEngineResigned) {
+				decision.resign, decision.err = true, nil
+			}
+		} else {
+			decision.move = engine.GenerateMove(ctx, position, color)
+		}
+		select {
+		case r.decisions <- decision:
+		case <-r.done:
+		}
+	}()
+}
+
+// stopEngine cancels the engine search in progress, if any. Its decision
+// still arrives, and is dropped as the game has moved on.
+func (r *GameRoom) stopEngine() {
+	if r.thinking != nil {
+		r.thinking()
+	}
+}
+
+// handleDecision plays an engine's decision, or drops it if the game has
+// changed since the engine started thinking. An engine whose move is
+// rejected passes, and one that failed is reported and resigns, as the
+// game cannot go on without it. The next engine to move, if any, is then
+// started.
+func (r *GameRoom) handleDecision(d engineDecision) {
+	r.thinking = nil
+	if d.node != r.Game.Current || d.color != r.Game.CurrentTurn || r.Game.IsOver || r.Game.Scoring {
+		r.startEngine()
+		return
+	}
+
+	switch {
+	case d.err != nil:
+		r.broadcast(Message{
+			Type: "engine_error",
+			Data: map[string]interface{}{
+				"color":   d.color.String(),
+				"message": d.err.Error(),
+			},
+		})
+		r.Game.Resign(d.color)
+		r.broadcastResign(d.color)
+		return
+	case d.resign:
+		r.Game.Resign(d.color)
+		r.broadcastResign(d.color)
+		return
+	case d.move != nil && r.Game.MakeMove(*d.move, d.color) == nil:
+		r.broadcastMove(*d.move, d.color)
+	default:
+		r.Game.Pass(d.color)
+		r.broadcastPass(d.color)
+	}
+	r.startEngine()
+}
+
+// closeEngines shuts down engines that hold resources, such as external
+// GTP processes, after cancelling any search they are running.
+func (r *GameRoom) closeEngines() {
+	r.stopEngine()
+	for _, engine := range r.Engines {
+		if closer, ok := engine.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+func (r *GameRoom) broadcastResign(color game.Color) {
+	r.broadcast(Message{
+		Type: "resign",
+		Data: map[string]interface{}{
+			"color":  color.String(),
+			"winner": game.OpponentColor(color).String(),
+		},
+	})
+	r.closeEngines()
+}
+
+// handleUndo takes back the last move. An engine whose move it was
+// chooses again.
+func (r *GameRoom) handleUndo(c *Client) {
+	if !r.Game.Undo() {
+		c.sendError("Cannot undo")
+		return
+	}
+	r.stopEngine()
+
+	r.broadcast(Message{
+		Type: "undo",
+		Data: map[string]interface{}{
+			"board": r.Game.GetBoardState(),
+			"info":  r.Game.GetGameInfo(),
+		},
+	})
+	r.startEngine()
+}
+
+// handleRedo plays the undone move or pass again, telling the players as
+// if it had just been played, so a redone pass can end play.
+func (r *GameRoom) handleRedo(c *Client) {
+	if !r.Game.Redo() {
+		c.sendError("Cannot redo")
+		return
+	}
+	r.stopEngine()
+
+	node := r.Game.Current
+	if node.Move != nil {
+		r.broadcastMove(*node.Move, node.Player)
+	} else {
+		r.broadcastPass(node.Player)
+	}
+	r.startEngine()
+}
+
+func (r *GameRoom) handleGetValidMoves(c *Client, color game.Color) {
+	movesData := []map[string]int{}
+	for _, move := range r.Game.GetValidMoves(color) {
+		movesData = append(movesData, map[string]int{
+			"x": move.X,
+			"y": move.Y,
+		})
+	}
+
+	c.sendMessage(Message{
+		Type: "valid_moves",
+		Data: map[string]interface{}{
+			"moves": movesData,
+		},
+	})
+}
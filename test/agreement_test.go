@@ -0,0 +1,112 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+// scoringGame is splitBoard with a lone White stone inside Black's area,
+// ended by two passes so that the players must agree on dead stones.
+func scoringGame(t *testing.T) *game.Game {
+	t.Helper()
+	g := splitBoard()
+	g.Board.SetStone(game.Point{X: 1, Y: 1}, game.White)
+	g.Rules.Komi = 0.5
+	g.AgreeDeadStones = true
+
+	if err := g.Pass(game.Black); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Pass(game.White); err != nil {
+		t.Fatal(err)
+	}
+	if !g.Scoring || g.IsOver {
+		t.Fatal("Expected two passes to start the scoring phase")
+	}
+	return g
+}
+
+func TestDeadStoneAgreement(t *testing.T) {
+	g := scoringGame(t)
+
+	if err := g.MakeMove(game.Point{X: 4, Y: 4}, game.Black); err != game.ErrScoringPhase {
+		t.Errorf("Expected ErrScoringPhase for a move, got %v", err)
+	}
+	if err := g.ToggleDead(game.Point{X: 4, Y: 4}); err != game.ErrInvalidMove {
+		t.Errorf("Expected ErrInvalidMove for an empty point, got %v", err)
+	}
+
+	provisional := game.GetGameResult(g, g.Rules.ScoringMethod, g.Rules.Komi).Score
+	if provisional.Winner == nil || *provisional.Winner != game.White {
+		t.Errorf("Expected White to lead before marking, got %s", provisional.GetResult())
+	}
+
+	if err := g.AcceptScore(game.White); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.ToggleDead(game.Point{X: 1, Y: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if g.Accepted[game.White] {
+		t.Error("Expected a toggle to withdraw earlier acceptance")
+	}
+	if dead := g.DeadStoneList(); len(dead) != 1 || dead[0] != (game.Point{X: 1, Y: 1}) {
+		t.Errorf("Expected (1,1) marked dead, got %v", dead)
+	}
+
+	provisional = game.GetGameResult(g, g.Rules.ScoringMethod, g.Rules.Komi).Score
+	if provisional.GetResult() != "B+0.5" {
+		t.Errorf("Expected B+0.5 with the stone dead, got %s", provisional.GetResult())
+	}
+
+	g.AcceptScore(game.Black)
+	if g.IsOver {
+		t.Fatal("Expected the game to wait for White's acceptance")
+	}
+	g.AcceptScore(game.White)
+	if !g.IsOver || g.Scoring {
+		t.Fatal("Expected the game to end once both players accept")
+	}
+	if g.Winner == nil || *g.Winner != game.Black {
+		t.Errorf("Expected Black to win with the agreed dead stones, got %v", g.Winner)
+	}
+	if scores := g.GetGameInfo()["scores"].(map[string]interface{}); scores["result"] != "B+0.5" {
+		t.Errorf("Expected B+0.5 in game info, got %v", scores["result"])
+	}
+}
+
+func TestResumePlayAfterDisagreement(t *testing.T) {
+	g := scoringGame(t)
+	g.ToggleDead(game.Point{X: 1, Y: 1})
+
+	if err := g.ResumePlay(game.Black); err != nil {
+		t.Fatal(err)
+	}
+	if g.Scoring || g.DeadStones != nil {
+		t.Error("Expected resuming to clear the scoring phase")
+	}
+	if g.CurrentTurn != game.White {
+		t.Errorf("Expected the opponent of the disputing player to move, got %s", g.CurrentTurn)
+	}
+	if err := g.MakeMove(game.Point{X: 1, Y: 2}, game.White); err != nil {
+		t.Errorf("Expected play to resume, got %v", err)
+	}
+	if err := g.ResumePlay(game.Black); err != game.ErrNotScoring {
+		t.Errorf("Expected ErrNotScoring outside the scoring phase, got %v", err)
+	}
+}
+
+func TestUndoLeavesScoringPhase(t *testing.T) {
+	g := scoringGame(t)
+
+	if !g.Undo() {
+		t.Fatal("Expected undo to succeed")
+	}
+	if g.Scoring || g.Accepted != nil {
+		t.Error("Expected undo to leave the scoring phase")
+	}
+	if err := g.Pass(game.White); err != nil {
+		t.Errorf("Expected White to pass again, got %v", err)
+	}
+}
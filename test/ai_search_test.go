@@ -0,0 +1,60 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+func TestAISearchDeadline(t *testing.T) {
+	for _, difficulty := range []string{"hard", "mcts"} {
+		g := game.NewGame(19)
+		g.MakeMove(game.Point{X: 3, Y: 3}, game.Black)
+		g.MakeMove(game.Point{X: 15, Y: 15}, game.White)
+
+		ai := game.NewAI(game.Black, difficulty)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		start := time.Now()
+		move := ai.GetMoveContext(ctx, g)
+		elapsed := time.Since(start)
+		cancel()
+
+		if move == nil || g.ValidateMove(*move, game.Black) != nil {
+			t.Errorf("%s: expected a legal move when time runs out, got %v", difficulty, move)
+		}
+		if elapsed > 2*time.Second {
+			t.Errorf("%s: search took %v with a 200ms deadline", difficulty, elapsed)
+		}
+	}
+}
+
+func TestAISearchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, difficulty := range []string{"easy", "medium", "hard", "mcts"} {
+		g := game.NewGame(9)
+		move := game.NewAI(game.Black, difficulty).GetMoveContext(ctx, g)
+		if move == nil || g.ValidateMove(*move, game.Black) != nil {
+			t.Errorf("%s: expected a legal move from a cancelled search, got %v", difficulty, move)
+		}
+	}
+}
+
+func TestMCTSParallel(t *testing.T) {
+	g := game.NewGame(9)
+	ai := game.NewAI(game.White, "mcts")
+	ai.Threads = 4
+	ai.Playouts = 400
+
+	analysis := ai.Analyze(context.Background(), g, game.White)
+	visits := 0
+	for _, candidate := range analysis.Candidates {
+		visits += candidate.Visits
+	}
+	if visits == 0 || visits > 400 {
+		t.Errorf("Expected at most 400 visits over the candidates, got %d", visits)
+	}
+}
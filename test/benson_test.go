@@ -0,0 +1,91 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+func TestBensonTwoEyes(t *testing.T) {
+	board := boardFromRows(
+		".X.X...",
+		"XXXX...",
+		".......",
+		".......",
+		".......",
+		".......",
+		".......",
+	)
+
+	alive := game.Benson(board, game.Black)
+	if len(alive.Chains) != 1 || len(alive.Chains[0]) != 6 {
+		t.Fatalf("Expected one pass-alive chain of 6 stones, got %v", alive.Chains)
+	}
+	expected := []game.Point{{X: 0, Y: 0}, {X: 2, Y: 0}}
+	if len(alive.Territory) != 2 || alive.Territory[0] != expected[0] || alive.Territory[1] != expected[1] {
+		t.Errorf("Expected territory %v, got %v", expected, alive.Territory)
+	}
+
+	if white := game.Benson(board, game.White); len(white.Chains) != 0 || len(white.Territory) != 0 {
+		t.Errorf("Expected nothing pass-alive for White, got %+v", white)
+	}
+}
+
+func TestBensonFalseEye(t *testing.T) {
+	// (2,0) looks like an eye, but the stone at (3,0) is in atari, so
+	// White can capture it and the eye is false.
+	board := boardFromRows(
+		".X.XO..",
+		"XXXO...",
+		".......",
+		".......",
+		".......",
+		".......",
+		".......",
+	)
+
+	if alive := game.Benson(board, game.Black); len(alive.Chains) != 0 {
+		t.Errorf("Expected no pass-alive chains with a false eye, got %v", alive.Chains)
+	}
+}
+
+func TestMarkDeadStonesUsesBenson(t *testing.T) {
+	// Two-point eyes fool the single-point eye heuristic, but Benson's
+	// algorithm proves the group alive and the White stone inside dead.
+	board := boardFromRows(
+		"..XO.XX",
+		"XXXXXXX",
+		".......",
+		".......",
+		".......",
+		".......",
+		".......",
+	)
+
+	dead := game.MarkDeadStones(board)
+	if !dead.Stones[game.Point{X: 3, Y: 0}] {
+		t.Error("Expected the White stone in pass-alive territory to be dead")
+	}
+	if dead.Stones[game.Point{X: 0, Y: 1}] {
+		t.Error("Expected the pass-alive Black group not to be marked dead")
+	}
+}
+
+func TestAIAvoidsPassAliveTerritory(t *testing.T) {
+	g := game.NewGame(5)
+	for x := 0; x < 5; x++ {
+		for y := 0; y < 5; y++ {
+			if y != 0 || x != 0 && x != 2 {
+				g.Board.SetStone(game.Point{X: x, Y: y}, game.Black)
+			}
+		}
+	}
+
+	// Black could only fill its own eyes.
+	for _, difficulty := range []string{"random", "easy", "medium", "hard", "mcts"} {
+		ai := game.NewAI(game.Black, difficulty, game.WithSeed(1), game.WithPlayouts(50))
+		if move := ai.GetMove(g); move != nil {
+			t.Errorf("%s: expected a pass, got %v", difficulty, *move)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package test
+
+import (
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+// The naive* helpers are the flood-fill implementation Board used before
+// it tracked chains incrementally. They serve as a reference for
+// correctness checks and as the baseline in benchmarks.
+
+func naiveGroup(b *game.Board, p game.Point) []game.Point {
+	color := b.GetColor(p)
+	if color == game.Empty {
+		return []game.Point{}
+	}
+
+	group := []game.Point{}
+	visited := make(map[game.Point]bool)
+	queue := []game.Point{p}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		group = append(group, current)
+
+		for _, neighbor := range b.GetNeighbors(current) {
+			if !visited[neighbor] && b.GetColor(neighbor) == color {
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+
+	return group
+}
+
+func naiveLiberties(b *game.Board, group []game.Point) int {
+	liberties := make(map[game.Point]bool)
+	for _, stone := range group {
+		for _, neighbor := range b.GetNeighbors(stone) {
+			if b.GetColor(neighbor) == game.Empty {
+				liberties[neighbor] = true
+			}
+		}
+	}
+	return len(liberties)
+}
+
+// naiveIsLegal mirrors the old ValidateMove: clone the grid, place the
+// stone, capture by scanning the whole board and check for liberties.
+func naiveIsLegal(b *game.Board, p game.Point, color game.Color) bool {
+	if b.GetColor(p) != game.Empty {
+		return false
+	}
+
+	temp := copyGrid(b)
+	temp.Grid[p.X][p.Y] = color
+
+	opponent := game.OpponentColor(color)
+	captured := 0
+	for x := 0; x < temp.Width; x++ {
+		for y := 0; y < temp.Height; y++ {
+			q := game.Point{X: x, Y: y}
+			if temp.Grid[x][y] == opponent {
+				group := naiveGroup(temp, q)
+				if naiveLiberties(temp, group) == 0 {
+					for _, stone := range group {
+						temp.Grid[stone.X][stone.Y] = game.Empty
+					}
+					captured += len(group)
+				}
+			}
+		}
+	}
+
+	return captured > 0 || naiveLiberties(temp, naiveGroup(temp, p)) > 0
+}
+
+// copyGrid returns a board sharing nothing with b but its dimensions and
+// stones. Its chains are not maintained, so only grid reads are valid.
+func copyGrid(b *game.Board) *game.Board {
+	grid := make([][]game.Color, b.Width)
+	for i := range grid {
+		grid[i] = make([]game.Color, b.Height)
+		copy(grid[i], b.Grid[i])
+	}
+	return &game.Board{Width: b.Width, Height: b.Height, Grid: grid}
+}
+
+// playRandomGame plays up to moves random legal moves on a fresh game.
+func playRandomGame(size, moves int, rng *rand.Rand) *game.Game {
+	g := game.NewGame(size)
+	for i := 0; i < moves; i++ {
+		valid := g.GetValidMoves(g.CurrentTurn)
+		if len(valid) == 0 {
+			g.Pass(g.CurrentTurn)
+			if g.IsOver {
+				break
+			}
+			continue
+		}
+		g.MakeMove(valid[rng.Intn(len(valid))], g.CurrentTurn)
+	}
+	return g
+}
+
+func TestChainTrackingMatchesFloodFill(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 20; i++ {
+		g := playRandomGame(9, 120, rng)
+		b := g.Board
+
+		for x := 0; x < b.Width; x++ {
+			for y := 0; y < b.Height; y++ {
+				p := game.Point{X: x, Y: y}
+
+				expected := naiveGroup(b, p)
+				group := b.GetGroup(p)
+				if len(group) != len(expected) {
+					t.Fatalf("Group at %v: expected %d stones, got %d", p, len(expected), len(group))
+				}
+
+				if len(group) > 0 {
+					if got, want := len(b.GetLiberties(group)), naiveLiberties(b, expected); got != want {
+						t.Fatalf("Liberties at %v: expected %d, got %d", p, want, got)
+					}
+				}
+
+				if color := g.CurrentTurn; naiveIsLegal(b, p, color) != (b.GetColor(p) == game.Empty && !b.IsSuicide(p, color)) {
+					t.Fatalf("Legality at %v disagrees with flood fill", p)
+				}
+			}
+		}
+
+		clone := b.Clone()
+		for x := 0; x < b.Width; x++ {
+			for y := 0; y < b.Height; y++ {
+				p := game.Point{X: x, Y: y}
+				if len(clone.GetGroup(p)) != len(b.GetGroup(p)) || clone.LibertyCount(p) != b.LibertyCount(p) {
+					t.Fatalf("Clone disagrees with original at %v", p)
+				}
+			}
+		}
+	}
+}
+
+func benchmarkPosition(size int) *game.Game {
+	return playRandomGame(size, size*size/2, rand.New(rand.NewSource(42)))
+}
+
+func BenchmarkGetGroupLiberties19(b *testing.B) {
+	board := benchmarkPosition(19).Board
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for x := 0; x < board.Width; x++ {
+			for y := 0; y < board.Height; y++ {
+				board.HasLiberties(game.Point{X: x, Y: y})
+			}
+		}
+	}
+}
+
+func BenchmarkGetGroupLiberties19FloodFill(b *testing.B) {
+	board := benchmarkPosition(19).Board
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for x := 0; x < board.Width; x++ {
+			for y := 0; y < board.Height; y++ {
+				naiveLiberties(board, naiveGroup(board, game.Point{X: x, Y: y}))
+			}
+		}
+	}
+}
+
+func BenchmarkValidMoves19(b *testing.B) {
+	g := benchmarkPosition(19)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		g.GetValidMoves(g.CurrentTurn)
+	}
+}
+
+func BenchmarkValidMoves19FloodFill(b *testing.B) {
+	g := benchmarkPosition(19)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for x := 0; x < g.Board.Width; x++ {
+			for y := 0; y < g.Board.Height; y++ {
+				naiveIsLegal(g.Board, game.Point{X: x, Y: y}, g.CurrentTurn)
+			}
+		}
+	}
+}
+
+func BenchmarkRandomGame19(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		playRandomGame(19, 200, rand.New(rand.NewSource(int64(i))))
+	}
+}
+
+// BenchmarkLongGameMemory19 reports the heap each 300-move 19x19 game
+// keeps alive, most of which is its history.
+func BenchmarkLongGameMemory19(b *testing.B) {
+	games := make([]*game.Game, b.N)
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	for i := range games {
+		games[i] = playRandomGame(19, 300, rand.New(rand.NewSource(int64(i))))
+	}
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc))/float64(b.N), "heap-bytes/game")
+	runtime.KeepAlive(games)
+}
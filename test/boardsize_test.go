@@ -0,0 +1,120 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+func TestValidateBoardSize(t *testing.T) {
+	valid := [][2]int{{2, 2}, {19, 19}, {9, 5}, {52, 52}, {2, 52}}
+	for _, size := range valid {
+		if err := game.ValidateBoardSize(size[0], size[1]); err != nil {
+			t.Errorf("Expected %dx%d to be valid, got %v", size[0], size[1], err)
+		}
+	}
+
+	invalid := [][2]int{{0, 0}, {-1, 9}, {1, 1}, {9, 1}, {53, 19}, {19, 53}}
+	for _, size := range invalid {
+		if err := game.ValidateBoardSize(size[0], size[1]); err != game.ErrInvalidBoardSize {
+			t.Errorf("Expected ErrInvalidBoardSize for %dx%d, got %v", size[0], size[1], err)
+		}
+		if _, err := game.NewRectangularGame(size[0], size[1]); err != game.ErrInvalidBoardSize {
+			t.Errorf("Expected NewRectangularGame(%d, %d) to fail, got %v", size[0], size[1], err)
+		}
+	}
+}
+
+func TestNewGameInvalidSizePanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r != game.ErrInvalidBoardSize {
+			t.Errorf("Expected a panic with ErrInvalidBoardSize, got %v", r)
+		}
+	}()
+	game.NewGame(0)
+}
+
+func TestRectangularGame(t *testing.T) {
+	g, err := game.NewRectangularGame(9, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Board.Width != 9 || g.Board.Height != 5 || len(g.Board.Grid) != 9 || len(g.Board.Grid[0]) != 5 {
+		t.Fatalf("Expected a 9x5 grid, got %dx%d", g.Board.Width, g.Board.Height)
+	}
+
+	if err := g.MakeMove(game.Point{X: 8, Y: 4}, game.Black); err != nil {
+		t.Errorf("Expected the far corner to be playable, got %v", err)
+	}
+	if err := g.MakeMove(game.Point{X: 4, Y: 8}, game.White); err != game.ErrInvalidMove {
+		t.Errorf("Expected ErrInvalidMove below the board, got %v", err)
+	}
+
+	// White captures the corner stone with two moves.
+	g.MakeMove(game.Point{X: 7, Y: 4}, game.White)
+	g.MakeMove(game.Point{X: 0, Y: 0}, game.Black)
+	g.MakeMove(game.Point{X: 8, Y: 3}, game.White)
+	if g.Board.GetColor(game.Point{X: 8, Y: 4}) != game.Empty || g.Board.Captures[game.White] != 1 {
+		t.Error("Expected the corner stone to be captured")
+	}
+
+	if err := g.SetFixedHandicap(2); err != game.ErrInvalidHandicap {
+		t.Errorf("Expected no fixed handicap on a rectangular board, got %v", err)
+	}
+	info := g.GetGameInfo()
+	if info["boardWidth"] != 9 || info["boardHeight"] != 5 {
+		t.Errorf("Expected the info to give 9x5, got %v x %v", info["boardWidth"], info["boardHeight"])
+	}
+}
+
+func TestBoardStringLabels(t *testing.T) {
+	board := game.NewRectangularBoard(3, 2)
+	board.SetStone(game.Point{X: 0, Y: 0}, game.Black)
+	board.SetStone(game.Point{X: 2, Y: 1}, game.White)
+
+	expected := "   A B C \n" +
+		" 2 ● . . 2\n" +
+		" 1 . . ○ 1\n" +
+		"   A B C "
+	if s := board.String(); s != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, s)
+	}
+
+	header := strings.SplitN(game.NewBoard(19).String(), "\n", 2)[0]
+	if strings.Contains(header, "I") || !strings.Contains(header, "H J") || !strings.HasSuffix(header, "T ") {
+		t.Errorf("Expected column labels to skip I, got %q", header)
+	}
+
+	header = strings.SplitN(game.NewBoard(52).String(), "\n", 2)[0]
+	if fields := strings.Fields(header); len(fields) != 52 || fields[24] != "Z" || fields[25] != "AA" || fields[51] != "BB" {
+		t.Errorf("Expected two-letter labels past Z, got %q", header)
+	}
+}
+
+func TestRectangularSGF(t *testing.T) {
+	records, err := game.LoadSGF(strings.NewReader("(;SZ[9:5];B[ie];W[ae])"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := records[0].GameAt(records[0].LastNode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Board.Width != 9 || g.Board.Height != 5 {
+		t.Fatalf("Expected a 9x5 game, got %dx%d", g.Board.Width, g.Board.Height)
+	}
+	if g.Board.GetColor(game.Point{X: 8, Y: 4}) != game.Black || g.Board.GetColor(game.Point{X: 0, Y: 4}) != game.White {
+		t.Error("Expected the moves at the bottom corners")
+	}
+
+	if text := game.NewRecordFromGame(g).SGF(); !strings.Contains(text, "SZ[9:5]") {
+		t.Errorf("Expected SZ[9:5] in %s", text)
+	}
+
+	for _, input := range []string{"(;SZ[53])", "(;SZ[9:0])"} {
+		if _, err := game.LoadSGF(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error for %s", input)
+		}
+	}
+}
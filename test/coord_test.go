@@ -0,0 +1,135 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/coord"
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+func TestCoordColumns(t *testing.T) {
+	tests := []struct {
+		x     int
+		label string
+	}{
+		{0, "A"}, {7, "H"}, {8, "J"}, {24, "Z"}, {25, "AA"}, {26, "AB"}, {49, "AZ"}, {50, "BA"}, {51, "BB"},
+	}
+	for _, tt := range tests {
+		if label := coord.Column(tt.x); label != tt.label {
+			t.Errorf("Column(%d) = %s, expected %s", tt.x, label, tt.label)
+		}
+		if x, ok := coord.ParseColumn(strings.ToLower(tt.label)); !ok || x != tt.x {
+			t.Errorf("ParseColumn(%s) = %d, %v, expected %d", tt.label, x, ok, tt.x)
+		}
+	}
+
+	for _, label := range []string{"I", "", "AI", "ABC", "1"} {
+		if _, ok := coord.ParseColumn(label); ok {
+			t.Errorf("Expected %q not to be a column", label)
+		}
+	}
+}
+
+func TestCoordLabels(t *testing.T) {
+	size := coord.Size{Width: 9, Height: 5}
+	tests := []struct {
+		label string
+		point coord.Point
+	}{
+		{"A5", coord.Point{X: 0, Y: 0}},
+		{"A1", coord.Point{X: 0, Y: 4}},
+		{"J1", coord.Point{X: 8, Y: 4}},
+		{"E3", coord.Point{X: 4, Y: 2}},
+	}
+	for _, tt := range tests {
+		if label := size.Label(tt.point); label != tt.label {
+			t.Errorf("Label(%v) = %s, expected %s", tt.point, label, tt.label)
+		}
+		if p, err := size.ParseLabel(tt.label); err != nil || p != tt.point {
+			t.Errorf("ParseLabel(%s) = %v, %v", tt.label, p, err)
+		}
+	}
+
+	wide := coord.Size{Width: 52, Height: 52}
+	if p, err := wide.ParseLabel("bb52"); err != nil || p != (coord.Point{X: 51, Y: 0}) || wide.Label(p) != "BB52" {
+		t.Errorf("Expected BB52 at the top right, got %v, %v", p, err)
+	}
+
+	for _, label := range []string{"A6", "A0", "K1", "Z1"} {
+		if _, err := size.ParseLabel(label); !errors.Is(err, coord.ErrOffBoard) {
+			t.Errorf("Expected ErrOffBoard for %s, got %v", label, err)
+		}
+	}
+	for _, label := range []string{"", "5", "I3", "A", "A3b"} {
+		if _, err := size.ParseLabel(label); !errors.Is(err, coord.ErrInvalid) {
+			t.Errorf("Expected ErrInvalid for %q, got %v", label, err)
+		}
+	}
+}
+
+func TestCoordVertex(t *testing.T) {
+	size := coord.Size{Width: 19, Height: 19}
+	if v := size.Vertex(nil); v != "pass" {
+		t.Errorf("Expected a nil point to be pass, got %s", v)
+	}
+	if v := size.Vertex(&coord.Point{X: 3, Y: 15}); v != "D4" {
+		t.Errorf("Expected D4, got %s", v)
+	}
+	if _, pass, err := size.ParseVertex("Pass"); !pass || err != nil {
+		t.Errorf("Expected Pass to parse as a pass, got %v, %v", pass, err)
+	}
+	if p, pass, err := size.ParseVertex("q16"); pass || err != nil || p != (coord.Point{X: 15, Y: 3}) {
+		t.Errorf("ParseVertex(q16) = %v, %v, %v", p, pass, err)
+	}
+}
+
+func TestCoordSGF(t *testing.T) {
+	size := coord.Size{Width: 19, Height: 19}
+	if s := coord.SGF(coord.Point{X: 3, Y: 15}); s != "dp" {
+		t.Errorf("Expected dp, got %s", s)
+	}
+	for _, pass := range []string{"", "tt"} {
+		if _, ok, err := size.ParseSGF(pass); !ok || err != nil {
+			t.Errorf("Expected %q to be a pass, got %v, %v", pass, ok, err)
+		}
+	}
+	if _, _, err := size.ParseSGF("ss"); err != nil {
+		t.Errorf("Expected ss on the board, got %v", err)
+	}
+	if _, _, err := size.ParseSGF("d"); !errors.Is(err, coord.ErrInvalid) {
+		t.Errorf("Expected ErrInvalid, got %v", err)
+	}
+
+	// tt is a point, not a pass, on boards wider than 19.
+	wide := coord.Size{Width: 52, Height: 21}
+	if p, pass, err := wide.ParseSGF("tt"); pass || err != nil || p != (coord.Point{X: 19, Y: 19}) {
+		t.Errorf("ParseSGF(tt) = %v, %v, %v", p, pass, err)
+	}
+	if p, _, err := wide.ParseSGF("Zu"); err != nil || p != (coord.Point{X: 51, Y: 20}) || coord.SGF(p) != "Zu" {
+		t.Errorf("Expected Zu at the bottom right, got %v, %v", p, err)
+	}
+	if _, _, err := wide.ParseSGF("av"); !errors.Is(err, coord.ErrOffBoard) {
+		t.Errorf("Expected ErrOffBoard, got %v", err)
+	}
+}
+
+func TestBoardSizeCoordinates(t *testing.T) {
+	g, err := game.NewRectangularGame(13, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := g.Board.Size(); size.Width != 13 || size.Height != 7 {
+		t.Errorf("Expected 13x7, got %dx%d", size.Width, size.Height)
+	}
+
+	// The labels String draws agree with the board's coordinates.
+	g.Board.SetStone(game.Point{X: 9, Y: 1}, game.Black)
+	lines := strings.Split(g.Board.String(), "\n")
+	columns := strings.Fields(lines[0])
+	row := strings.Fields(lines[2])
+	if label := columns[9] + row[0]; label != g.Board.Label(game.Point{X: 9, Y: 1}) || label != "K6" || row[10] != "●" {
+		t.Errorf("Expected the stone at K6, got %s in %q", label, lines[2])
+	}
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+	"github.com/Prawal-Sharma/GoSim/pkg/gtp"
+)
+
+func TestEngineRegistry(t *testing.T) {
+	names := map[string]game.EngineInfo{}
+	for _, info := range game.Engines() {
+		names[info.Name] = info
+	}
+	for _, name := range []string{"random", "easy", "medium", "hard", "mcts"} {
+		if _, ok := names[name]; !ok {
+			t.Errorf("Engine %s should be registered", name)
+		}
+	}
+
+	hasTimeBudget := false
+	for _, capability := range names["mcts"].Capabilities {
+		hasTimeBudget = hasTimeBudget || capability == game.CapabilityTimeBudget
+	}
+	if !hasTimeBudget {
+		t.Error("mcts should advertise a time budget")
+	}
+
+	if _, err := game.NewEngine("nonexistent"); err != game.ErrUnknownEngine {
+		t.Errorf("Expected ErrUnknownEngine, got %v", err)
+	}
+
+	engine, err := game.NewEngine("easy")
+	if err != nil || engine.Name() != "easy" {
+		t.Fatalf("Expected easy engine, got %v, %v", engine, err)
+	}
+	g := game.NewGame(9)
+	if move := engine.GenerateMove(context.Background(), g, game.Black); move == nil || g.ValidateMove(*move, game.Black) != nil {
+		t.Errorf("Easy engine returned invalid move %v", move)
+	}
+}
+
+func TestScriptedEngine(t *testing.T) {
+	moves := []*game.Point{{X: 2, Y: 2}, {X: 2, Y: 2}}
+	game.RegisterEngine(game.EngineInfo{Name: "test-script"}, func() game.Engine {
+		return game.NewScriptedEngine("test-script", moves...)
+	})
+
+	engine, err := game.NewEngine("test-script")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := game.NewGame(9)
+	move := engine.GenerateMove(context.Background(), g, game.Black)
+	if move == nil || *move != (game.Point{X: 2, Y: 2}) {
+		t.Fatalf("Expected scripted move, got %v", move)
+	}
+	g.MakeMove(*move, game.Black)
+
+	if move := engine.GenerateMove(context.Background(), g, game.White); move != nil {
+		t.Errorf("An illegal scripted move should become a pass, got %v", move)
+	}
+	if move := engine.GenerateMove(context.Background(), g, game.White); move != nil {
+		t.Errorf("An exhausted script should pass, got %v", move)
+	}
+}
+
+func TestEngineAnalysis(t *testing.T) {
+	engine, _ := game.NewEngine("mcts")
+	analyzer, ok := engine.(game.Analyzer)
+	if !ok {
+		t.Fatal("mcts should implement Analyzer")
+	}
+	engine.(*game.AI).Playouts = 200
+
+	analysis := analyzer.Analyze(context.Background(), game.NewGame(9), game.Black)
+	if len(analysis.Candidates) == 0 || analysis.WinRate <= 0 || analysis.WinRate >= 1 {
+		t.Fatalf("Unexpected analysis: %+v", analysis)
+	}
+	for i := 1; i < len(analysis.Candidates); i++ {
+		if analysis.Candidates[i].Visits > analysis.Candidates[i-1].Visits {
+			t.Error("Candidates should be sorted by visits")
+		}
+	}
+}
+
+func TestGTPEngineRegistration(t *testing.T) {
+	gtp.RegisterEngine("test-fake", fakeEngine(t), "-moves", "C3")
+
+	engine, err := game.NewEngine("test-fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer engine.(io.Closer).Close()
+
+	move := engine.GenerateMove(context.Background(), game.NewGame(9), game.White)
+	if move == nil || *move != (game.Point{X: 2, Y: 6}) {
+		t.Errorf("Expected C3 from the GTP engine, got %v", move)
+	}
+}
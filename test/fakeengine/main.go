@@ -0,0 +1,93 @@
+// Command fakeengine is a scripted GTP engine used to test the GTP client.
+// It answers the basic commands, replies to genmove from a fixed list of
+// vertices and can be told to crash or hang once to exercise restarts.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+func main() {
+	moves := flag.String("moves", "pass", "comma-separated replies to genmove, used in turn")
+	crashOnce := flag.String("crash-once", "", "exit on the first genmove if this file does not exist yet")
+	hangOnce := flag.String("hang-once", "", "stop responding on the first genmove if this file does not exist yet")
+	logFile := flag.String("log", "", "append every command received to this file")
+	flag.Parse()
+
+	replies := strings.Split(*moves, ",")
+	next := 0
+
+	var log *os.File
+	if *logFile != "" {
+		var err error
+		log, err = os.OpenFile(*logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer log.Close()
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		id := ""
+		if fields[0][0] >= '0' && fields[0][0] <= '9' {
+			id, fields = fields[0], fields[1:]
+		}
+		if log != nil {
+			fmt.Fprintln(log, strings.Join(fields, " "))
+		}
+
+		switch fields[0] {
+		case "protocol_version":
+			respond(id, "2")
+		case "name":
+			respond(id, "Fake")
+		case "version":
+			respond(id, "0.1")
+		case "boardsize", "clear_board", "komi", "play":
+			respond(id, "")
+		case "genmove":
+			if firstTime(*crashOnce) {
+				os.Exit(1)
+			}
+			if firstTime(*hangOnce) {
+				time.Sleep(time.Hour)
+			}
+			respond(id, replies[next%len(replies)])
+			next++
+		case "quit":
+			respond(id, "")
+			return
+		default:
+			fmt.Printf("?%s unknown command\n\n", id)
+		}
+	}
+}
+
+func respond(id, result string) {
+	fmt.Printf("=%s %s\n\n", id, result)
+}
+
+// firstTime reports whether marker names a file that does not exist yet,
+// creating it so that the next call returns false.
+func firstTime(marker string) bool {
+	if marker == "" {
+		return false
+	}
+	if _, err := os.Stat(marker); err == nil {
+		return false
+	}
+	os.WriteFile(marker, nil, 0o644)
+	return true
+}
@@ -0,0 +1,189 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+	"github.com/Prawal-Sharma/GoSim/pkg/gtp"
+)
+
+var fakeEngineBuild struct {
+	once sync.Once
+	dir  string
+	path string
+	err  error
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if fakeEngineBuild.dir != "" {
+		os.RemoveAll(fakeEngineBuild.dir)
+	}
+	os.Exit(code)
+}
+
+// fakeEngine builds test/fakeengine once per test run and returns the
+// path of the binary.
+func fakeEngine(t *testing.T) string {
+	t.Helper()
+
+	b := &fakeEngineBuild
+	b.once.Do(func() {
+		b.dir, b.err = os.MkdirTemp("", "gosim-fakeengine")
+		if b.err != nil {
+			return
+		}
+		b.path = filepath.Join(b.dir, "fakeengine")
+		if out, err := exec.Command("go", "build", "-o", b.path, "./fakeengine").CombinedOutput(); err != nil {
+			b.err = errors.New(string(out))
+		}
+	})
+	if b.err != nil {
+		t.Skipf("Cannot build fake engine: %v", b.err)
+	}
+	return b.path
+}
+
+func readLog(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Cannot read engine log: %v", err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestGTPClientGenMove(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "commands.log")
+	client := gtp.NewClient(game.White, fakeEngine(t), "-moves", "E5,pass", "-log", logPath)
+	defer client.Close()
+
+	g := game.NewGame(9)
+	g.Board.SetStone(game.Point{X: 2, Y: 6}, game.Black)
+	g.MakeMove(game.Point{X: 6, Y: 2}, game.Black)
+
+	move := client.GetMove(g)
+	if move == nil || *move != (game.Point{X: 4, Y: 4}) {
+		t.Fatalf("Expected E5, got %v", move)
+	}
+
+	g.MakeMove(*move, game.White)
+	g.MakeMove(game.Point{X: 0, Y: 0}, game.Black)
+
+	if move := client.GetMove(g); move != nil {
+		t.Errorf("Expected pass, got %v", move)
+	}
+
+	expected := []string{
+		"boardsize 9", "clear_board", "komi 6.5",
+		"play black C3", "play black G7", "genmove white",
+		"play black A9", "genmove white",
+	}
+	commands := readLog(t, logPath)
+	if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected commands:\n%s", strings.Join(commands, "\n"))
+	}
+}
+
+func TestGTPClientResyncsOnNewGame(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "commands.log")
+	client := gtp.NewClient(game.White, fakeEngine(t), "-moves", "pass", "-log", logPath)
+	defer client.Close()
+
+	g := game.NewGame(9)
+	g.MakeMove(game.Point{X: 4, Y: 4}, game.Black)
+	client.GetMove(g)
+
+	other := game.NewGame(9)
+	other.MakeMove(game.Point{X: 3, Y: 3}, game.Black)
+	client.GetMove(other)
+
+	commands := readLog(t, logPath)
+	if n := strings.Count(strings.Join(commands, "\n"), "clear_board"); n != 2 {
+		t.Errorf("Expected a full resync for a different game, got %d clear_board commands", n)
+	}
+}
+
+func TestGTPClientRestart(t *testing.T) {
+	dir := t.TempDir()
+	engine := fakeEngine(t)
+
+	crashing := gtp.NewClient(game.Black, engine, "-moves", "D4", "-crash-once", filepath.Join(dir, "crashed"))
+	defer crashing.Close()
+
+	move, err := crashing.GenMove(game.NewGame(9))
+	if err != nil || move == nil || *move != (game.Point{X: 3, Y: 5}) {
+		t.Errorf("Expected D4 after restart, got %v, %v", move, err)
+	}
+
+	hanging := gtp.NewClient(game.Black, engine, "-moves", "D4", "-hang-once", filepath.Join(dir, "hung"))
+	hanging.MoveTimeout = 200 * time.Millisecond
+	defer hanging.Close()
+
+	move, err = hanging.GenMove(game.NewGame(9))
+	if err != nil || move == nil {
+		t.Errorf("Expected a move after timeout and restart, got %v, %v", move, err)
+	}
+
+	noRestarts := gtp.NewClient(game.Black, engine, "-crash-once", filepath.Join(dir, "crashed-again"))
+	noRestarts.MaxRestarts = 0
+
+	if _, err := noRestarts.GenMove(game.NewGame(9)); !errors.Is(err, gtp.ErrEngineExited) {
+		t.Errorf("Expected ErrEngineExited, got %v", err)
+	}
+}
+
+func TestGTPClientContext(t *testing.T) {
+	client := gtp.NewClient(game.Black, fakeEngine(t), "-moves", "D4", "-hang-once", filepath.Join(t.TempDir(), "hung"))
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.GenMoveContext(ctx, game.NewGame(9)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to cancel genmove, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Cancellation took %v", elapsed)
+	}
+
+	if move, err := client.GenMove(game.NewGame(9)); err != nil || move == nil {
+		t.Errorf("Expected the engine to restart after cancellation, got %v, %v", move, err)
+	}
+}
+
+func TestGTPClientErrors(t *testing.T) {
+	client := gtp.NewClient(game.Black, fakeEngine(t), "-moves", "resign")
+	defer client.Close()
+
+	if name, err := client.Send("name"); err != nil || name != "Fake" {
+		t.Errorf("Expected name Fake, got %q, %v", name, err)
+	}
+
+	_, err := client.Send("foo")
+	var engineErr *gtp.EngineError
+	if !errors.As(err, &engineErr) || engineErr.Message != "unknown command" {
+		t.Errorf("Expected unknown command error, got %v", err)
+	}
+
+	if _, err := client.GenMove(game.NewGame(9)); err != gtp.ErrResign {
+		t.Errorf("Expected ErrResign, got %v", err)
+	}
+	if _, err := client.TryGenerateMove(context.Background(), game.NewGame(9), game.Black); !errors.Is(err, game.ErrEngineResigned) {
+		t.Errorf("Expected TryGenerateMove to report the resignation, got %v", err)
+	}
+
+	missing := gtp.NewClient(game.Black, filepath.Join(t.TempDir(), "missing"))
+	if _, err := missing.Send("name"); err == nil {
+		t.Error("Expected an error starting a missing engine")
+	}
+}
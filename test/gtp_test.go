@@ -0,0 +1,186 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+	"github.com/Prawal-Sharma/GoSim/pkg/gtp"
+)
+
+// runGTP feeds a script to a fresh engine and returns its responses, one
+// per command.
+func runGTP(t *testing.T, script string) []string {
+	t.Helper()
+
+	var out bytes.Buffer
+	engine := gtp.NewEngine("easy")
+	if err := engine.Run(strings.NewReader(script), &out); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	responses := strings.Split(strings.TrimSuffix(out.String(), "\n\n"), "\n\n")
+	return responses
+}
+
+func TestGTPVertex(t *testing.T) {
+	tests := []struct {
+		vertex string
+		point  game.Point
+	}{
+		{"A1", game.Point{X: 0, Y: 18}},
+		{"T19", game.Point{X: 18, Y: 0}},
+		{"J10", game.Point{X: 8, Y: 9}},
+		{"d4", game.Point{X: 3, Y: 15}},
+	}
+
+	for _, tt := range tests {
+		p, pass, err := gtp.ParseVertex(tt.vertex, 19)
+		if err != nil || pass || p != tt.point {
+			t.Errorf("ParseVertex(%s) = %v, %v, %v", tt.vertex, p, pass, err)
+		}
+		if v := gtp.FormatVertex(&tt.point, 19); v != strings.ToUpper(tt.vertex) {
+			t.Errorf("FormatVertex(%v) = %s, expected %s", tt.point, v, tt.vertex)
+		}
+	}
+
+	for _, vertex := range []string{"I5", "Z1", "A0", "A20", "5"} {
+		if _, _, err := gtp.ParseVertex(vertex, 19); err == nil {
+			t.Errorf("Expected error for %s", vertex)
+		}
+	}
+	if _, pass, _ := gtp.ParseVertex("PASS", 19); !pass {
+		t.Error("PASS should parse as a pass")
+	}
+}
+
+func TestGTPSession(t *testing.T) {
+	script := `protocol_version
+1 name
+# a comment line
+
+2 boardsize 9
+clear_board
+komi 0.5
+play black C3
+play white C3
+3 play w D3
+genmove b
+undo
+undo
+showboard
+foo
+boardsize 99
+quit
+play b A1
+`
+	responses := runGTP(t, script)
+
+	expected := []string{
+		"= 2",
+		"=1 GoSim",
+		"=2 ",
+		"= ",
+		"= ",
+		"= ",
+		"? illegal move",
+		"=3 ",
+	}
+	for i, want := range expected {
+		if responses[i] != want {
+			t.Errorf("Response %d: expected %q, got %q", i, want, responses[i])
+		}
+	}
+
+	if !strings.HasPrefix(responses[8], "= ") || len(responses[8]) < 4 {
+		t.Errorf("genmove should return a vertex, got %q", responses[8])
+	}
+	if responses[9] != "= " || responses[10] != "= " {
+		t.Errorf("undo should succeed twice, got %q and %q", responses[9], responses[10])
+	}
+	if !strings.Contains(responses[11], "●") || strings.Contains(responses[11], "○") {
+		t.Errorf("Only C3 should remain after undo:\n%s", responses[11])
+	}
+	if responses[12] != "? unknown command" || responses[13] != "? unacceptable size" {
+		t.Errorf("Unexpected errors: %q, %q", responses[12], responses[13])
+	}
+	if len(responses) != 15 {
+		t.Errorf("Commands after quit should be ignored, got %d responses", len(responses))
+	}
+}
+
+func TestGTPHandicap(t *testing.T) {
+	responses := runGTP(t, "fixed_handicap 4\nfixed_handicap 2\nclear_board\nboardsize 9\nset_free_handicap C3 G7 C3\nset_free_handicap C3 G7\nplace_free_handicap 3\nclear_board\nplace_free_handicap 11\nfixed_handicap 10\n")
+
+	if responses[0] != "= D4 Q16 D16 Q4" {
+		t.Errorf("Unexpected fixed handicap: %q", responses[0])
+	}
+	if responses[1] != "? board not empty" {
+		t.Errorf("Expected board not empty, got %q", responses[1])
+	}
+	if responses[4] != "? bad vertex list" || responses[5] != "= " {
+		t.Errorf("Unexpected set_free_handicap responses: %q, %q", responses[4], responses[5])
+	}
+	if responses[6] != "? board not empty" {
+		t.Errorf("Expected board not empty, got %q", responses[6])
+	}
+	if stones := strings.Fields(strings.TrimPrefix(responses[8], "=")); len(stones) != 11 {
+		t.Errorf("Expected 11 free handicap stones, got %q", responses[8])
+	}
+	if responses[9] != "? board not empty" {
+		t.Errorf("Expected board not empty, got %q", responses[9])
+	}
+}
+
+func TestGTPScoring(t *testing.T) {
+	script := `boardsize 5
+komi 0.5
+play b C1
+play b C2
+play b C3
+play b C4
+play b C5
+play w D1
+play w D2
+play w D3
+play w D4
+play w D5
+play b pass
+play w pass
+final_score
+final_status_list dead
+final_status_list alive
+gosim-ownership
+gogui-analyze_commands
+time_settings 300 30 5
+time_left b 120 0
+time_left x 1 1
+`
+	responses := runGTP(t, script)
+	n := len(responses)
+
+	if score := responses[n-8]; !strings.HasPrefix(score, "= B+") && !strings.HasPrefix(score, "= W+") && score != "= 0" {
+		t.Errorf("Unexpected final score: %q", score)
+	}
+
+	groups := 0
+	for _, list := range responses[n-7 : n-5] {
+		if list = strings.TrimPrefix(list, "= "); list != "" {
+			groups += len(strings.Split(list, "\n"))
+		}
+	}
+	if groups != 2 {
+		t.Errorf("Expected two groups between dead and alive, got %d", groups)
+	}
+
+	if !strings.HasPrefix(responses[n-5], "= INFLUENCE") {
+		t.Errorf("Unexpected ownership: %q", responses[n-5])
+	}
+	if !strings.Contains(responses[n-4], "gfx/Ownership/gosim-ownership") {
+		t.Errorf("Unexpected analyze commands: %q", responses[n-4])
+	}
+	if responses[n-3] != "= " || responses[n-2] != "= " || responses[n-1] != "? syntax error" {
+		t.Errorf("Unexpected time responses: %q", responses[n-3:])
+	}
+}
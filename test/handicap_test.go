@@ -0,0 +1,110 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+func TestFixedHandicapGame(t *testing.T) {
+	g := game.NewGame(19)
+	if err := g.SetFixedHandicap(4); err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Handicap != 4 || g.CurrentTurn != game.White {
+		t.Errorf("Expected handicap 4 with White to move, got %d and %s", g.Handicap, g.CurrentTurn)
+	}
+	for _, p := range []game.Point{{X: 3, Y: 3}, {X: 15, Y: 15}, {X: 3, Y: 15}, {X: 15, Y: 3}} {
+		if g.Board.GetColor(p) != game.Black {
+			t.Errorf("Expected a handicap stone at %v", p)
+		}
+	}
+
+	if err := g.MakeMove(game.Point{X: 9, Y: 9}, game.Black); err == nil {
+		t.Error("Black should not move first after handicap")
+	}
+	if err := g.MakeMove(game.Point{X: 9, Y: 9}, game.White); err != nil {
+		t.Errorf("White should move first: %v", err)
+	}
+	if err := g.SetFixedHandicap(2); err != game.ErrHandicapPlacement {
+		t.Errorf("Expected ErrHandicapPlacement after a move, got %v", err)
+	}
+
+	if err := game.NewGame(9).SetFixedHandicap(10); err != game.ErrInvalidHandicap {
+		t.Errorf("Expected ErrInvalidHandicap for 10 stones on 9x9, got %v", err)
+	}
+}
+
+func TestFreeHandicapPlacement(t *testing.T) {
+	g := game.NewGame(9)
+	if err := g.PlaceHandicap([]game.Point{{X: 1, Y: 1}}); err != game.ErrInvalidHandicap {
+		t.Errorf("Expected ErrInvalidHandicap for one stone, got %v", err)
+	}
+	if err := g.PlaceHandicap([]game.Point{{X: 1, Y: 1}, {X: 1, Y: 1}}); err != game.ErrInvalidMove {
+		t.Errorf("Expected ErrInvalidMove for a repeated point, got %v", err)
+	}
+	if err := g.PlaceHandicap([]game.Point{{X: 1, Y: 1}, {X: 7, Y: 2}, {X: 4, Y: 6}}); err != nil {
+		t.Fatal(err)
+	}
+	if g.Handicap != 3 || g.CurrentTurn != game.White {
+		t.Errorf("Expected handicap 3 with White to move, got %d and %s", g.Handicap, g.CurrentTurn)
+	}
+}
+
+func TestHandicapCompensation(t *testing.T) {
+	tests := []struct {
+		rule     game.HandicapCompensation
+		stones   int
+		expected float64
+	}{
+		{game.ChineseCompensation, 4, 4},
+		{game.AGACompensation, 4, 3},
+		{game.NoCompensation, 4, 0},
+		{game.ChineseCompensation, 0, 0},
+	}
+	for _, tt := range tests {
+		if points := tt.rule.Points(tt.stones); points != tt.expected {
+			t.Errorf("%s with %d stones: expected %v, got %v", tt.rule, tt.stones, tt.expected, points)
+		}
+	}
+
+	g := game.NewGame(9)
+	g.SetFixedHandicap(2)
+	g.Rules.HandicapCompensation = game.AGACompensation
+
+	area := game.GetGameResult(g, game.ChineseScoring, game.HandicapKomi).Score
+	if area.Compensation != 1 || area.White != float64(area.Territory[game.White])+game.HandicapKomi+1 {
+		t.Errorf("Area scoring should compensate White, got %+v", area)
+	}
+	territory := game.GetGameResult(g, game.JapaneseScoring, game.HandicapKomi).Score
+	if territory.Compensation != 0 {
+		t.Errorf("Territory scoring should not compensate White, got %v", territory.Compensation)
+	}
+}
+
+func TestHandicapSGF(t *testing.T) {
+	g := game.NewGame(9)
+	g.SetFixedHandicap(3)
+	g.MakeMove(game.Point{X: 4, Y: 4}, game.White)
+
+	sgfText := game.NewRecordFromGame(g).SGF()
+	for _, want := range []string{"HA[3]", "AB[", "PL[W]", ";W[ee]"} {
+		if !strings.Contains(sgfText, want) {
+			t.Errorf("Expected %s in %s", want, sgfText)
+		}
+	}
+
+	records, err := game.LoadSGF(strings.NewReader(sgfText))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := records[0].Game()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Handicap != 3 || loaded.CurrentTurn != game.Black {
+		t.Errorf("Expected handicap 3 with Black to move, got %d and %s", loaded.Handicap, loaded.CurrentTurn)
+	}
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+// snapshot is what Undo must restore after each move.
+type snapshot struct {
+	position string
+	hash     uint64
+	lastMove *game.Point
+	moves    int
+}
+
+func takeSnapshot(g *game.Game) snapshot {
+	return snapshot{g.Position(), g.Board.Hash, g.Board.LastMove, g.MoveCount}
+}
+
+func TestUndoReversesRandomGames(t *testing.T) {
+	for _, ruleset := range []string{"japanese", "aga", "new_zealand"} {
+		rng := rand.New(rand.NewSource(1))
+		for i := 0; i < 10; i++ {
+			g, _ := game.NewGameWithRuleset(7, ruleset)
+			var history []snapshot
+			for move := 0; move < 120; move++ {
+				history = append(history, takeSnapshot(g))
+				valid := g.GetValidMoves(g.CurrentTurn)
+				if len(valid) == 0 || rng.Intn(15) == 0 {
+					g.Passed[game.OpponentColor(g.CurrentTurn)] = false
+					g.Pass(g.CurrentTurn)
+					continue
+				}
+				g.MakeMove(valid[rng.Intn(len(valid))], g.CurrentTurn)
+			}
+
+			for len(history) > 0 {
+				expected := history[len(history)-1]
+				history = history[:len(history)-1]
+				if !g.Undo() {
+					t.Fatalf("%s game %d: Undo failed with %d moves left", ruleset, i, len(history)+1)
+				}
+				if got := takeSnapshot(g); got.position != expected.position || got.hash != expected.hash ||
+					got.moves != expected.moves || !samePoint(got.lastMove, expected.lastMove) {
+					t.Fatalf("%s game %d: Undo gave %+v, expected %+v", ruleset, i, got, expected)
+				}
+			}
+			if g.Undo() {
+				t.Errorf("%s game %d: expected nothing left to undo", ruleset, i)
+			}
+
+			// The chains are rebuilt correctly, so play goes on as normal.
+			if err := g.MakeMove(game.Point{X: 3, Y: 3}, game.Black); err != nil {
+				t.Errorf("%s game %d: %v", ruleset, i, err)
+			}
+		}
+	}
+}
+
+func TestUndoSuicide(t *testing.T) {
+	g, _ := game.NewGameWithRuleset(5, "new_zealand")
+	moves := []game.Point{
+		{X: 0, Y: 0}, {X: 2, Y: 0},
+		{X: 0, Y: 1}, {X: 1, Y: 1},
+		{X: 4, Y: 4}, {X: 0, Y: 2},
+	}
+	for _, p := range moves {
+		if err := g.MakeMove(p, g.CurrentTurn); err != nil {
+			t.Fatalf("MakeMove(%v): %v", p, err)
+		}
+	}
+	before := takeSnapshot(g)
+
+	if err := g.MakeMove(game.Point{X: 1, Y: 0}, game.Black); err != nil {
+		t.Fatal(err)
+	}
+	if g.Board.Captures[game.White] != 3 {
+		t.Fatalf("Expected the suicide to give White 3 prisoners, got %d", g.Board.Captures[game.White])
+	}
+
+	g.Undo()
+	if after := takeSnapshot(g); after.position != before.position || after.hash != before.hash {
+		t.Errorf("Expected undo to restore %s, got %s", before.position, after.position)
+	}
+	if g.Board.GetColor(game.Point{X: 0, Y: 0}) != game.Black || len(g.Board.GetGroup(game.Point{X: 0, Y: 0})) != 2 {
+		t.Error("Expected the two Black stones back as one group")
+	}
+}
+
+func TestInitialGridKeepsSetupStones(t *testing.T) {
+	g := game.NewGame(9)
+	if err := g.SetFixedHandicap(2); err != nil {
+		t.Fatal(err)
+	}
+	setup := g.Board.Diagram()
+
+	playRandomMoves(g, 40, rand.New(rand.NewSource(3)))
+	initial := &game.Board{Width: 9, Height: 9, Grid: g.Board.InitialGrid()}
+	if initial.Diagram() != setup {
+		t.Errorf("Expected the handicap stones alone, got\n%s", initial.Diagram())
+	}
+
+	// The record replays to the same position.
+	replayed, err := game.NewRecordFromGame(g).Game()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Board.Hash != g.Board.Hash {
+		t.Errorf("Expected the record to replay to\n%s\ngot\n%s", g.Board.Diagram(), replayed.Board.Diagram())
+	}
+}
+
+func playRandomMoves(g *game.Game, moves int, rng *rand.Rand) {
+	for i := 0; i < moves; i++ {
+		valid := g.GetValidMoves(g.CurrentTurn)
+		if len(valid) == 0 {
+			return
+		}
+		g.MakeMove(valid[rng.Intn(len(valid))], g.CurrentTurn)
+	}
+}
+
+func samePoint(a, b *game.Point) bool {
+	return a == b || (a != nil && b != nil && *a == *b)
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+// splitBoard gives Black the left four columns of a 9x9 board and White
+// the right four, leaving column 4 as neutral dame.
+func splitBoard() *game.Game {
+	g := game.NewGame(9)
+	for y := 0; y < 9; y++ {
+		g.Board.SetStone(game.Point{X: 3, Y: y}, game.Black)
+		g.Board.SetStone(game.Point{X: 5, Y: y}, game.White)
+	}
+	return g
+}
+
+func TestScoreUsesRulesKomi(t *testing.T) {
+	g := splitBoard()
+	g.Rules.Komi = 0.5
+
+	score := g.Score()
+	if score.Komi != 0.5 || score.Black != score.White-0.5 {
+		t.Errorf("Expected White to lead by the komi, got B %.1f W %.1f", score.Black, score.White)
+	}
+	if score.Winner == nil || *score.Winner != game.White {
+		t.Fatalf("Expected White to win by komi, got %v", score.Winner)
+	}
+	if score.GetResult() != "W+0.5" {
+		t.Errorf("Expected W+0.5, got %s", score.GetResult())
+	}
+
+	g.Rules.Komi = 0
+	if winner := g.Score().Winner; winner != nil {
+		t.Errorf("Expected a draw without komi, got %s", winner)
+	}
+
+	g.Rules.Komi = -1.5
+	if winner := g.Score().Winner; winner == nil || *winner != game.Black {
+		t.Errorf("Expected negative komi to favour Black, got %v", winner)
+	}
+}
+
+func TestScoringMethodInRules(t *testing.T) {
+	g := splitBoard()
+	g.Rules.Komi = 0
+	g.Board.Captures[game.Black] = 3
+
+	g.Rules.ScoringMethod = game.JapaneseScoring
+	if winner := g.Score().Winner; winner == nil || *winner != game.Black {
+		t.Errorf("Expected captures to decide a Japanese count, got %v", winner)
+	}
+
+	g.Rules.ScoringMethod = game.ChineseScoring
+	score := g.Score()
+	if score.Black != 36 || score.Winner != nil {
+		t.Errorf("Expected an even area count of 36, got B %.1f W %.1f", score.Black, score.White)
+	}
+}
+
+func TestEndGameWinnerAndInfo(t *testing.T) {
+	g := splitBoard()
+	g.Rules.Komi = 7.5
+	g.Rules.ScoringMethod = game.ChineseScoring
+
+	if err := g.Pass(game.Black); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Pass(game.White); err != nil {
+		t.Fatal(err)
+	}
+	if g.Winner == nil || *g.Winner != game.White {
+		t.Fatalf("Expected White to win on komi, got %v", g.Winner)
+	}
+
+	info := g.GetGameInfo()
+	if info["komi"] != 7.5 || info["rules"] != game.ChineseScoring {
+		t.Errorf("Expected komi and rules in info, got %v and %v", info["komi"], info["rules"])
+	}
+	scores, ok := info["scores"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected scores in info, got %v", info["scores"])
+	}
+	if scores["White"] != 43.5 || scores["result"] != "W+7.5" {
+		t.Errorf("Unexpected scores %v", scores)
+	}
+}
+
+func TestParseScoringMethod(t *testing.T) {
+	method, err := game.ParseScoringMethod("Chinese")
+	if err != nil || method != game.ChineseScoring {
+		t.Errorf("Expected chinese, got %q (%v)", method, err)
+	}
+	if _, err := game.ParseScoringMethod("lunar"); err != game.ErrUnknownRules {
+		t.Errorf("Expected ErrUnknownRules, got %v", err)
+	}
+}
+
+func TestKomiAndRulesSGFRoundTrip(t *testing.T) {
+	g := game.NewGame(9)
+	g.Rules.Komi = 5.5
+	g.Rules.ScoringMethod = game.ChineseScoring
+
+	text := game.NewRecordFromGame(g).SGF()
+	for _, expected := range []string{"KM[5.5]", "RU[Chinese]"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %s in %s", expected, text)
+		}
+	}
+
+	records, err := game.LoadSGF(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := records[0].Game()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Rules.Komi != 5.5 || loaded.Rules.ScoringMethod != game.ChineseScoring {
+		t.Errorf("Expected komi 5.5 under Chinese rules, got %.1f under %s", loaded.Rules.Komi, loaded.Rules.ScoringMethod)
+	}
+}
@@ -0,0 +1,63 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+func TestMCTSMove(t *testing.T) {
+	g := game.NewGame(9)
+	ai := game.NewAI(game.Black, "mcts")
+	ai.Playouts = 300
+
+	for i := 0; i < 4; i++ {
+		color := g.CurrentTurn
+		ai.Color = color
+		move := ai.GetMove(g)
+		if move == nil {
+			t.Fatalf("MCTS should not pass at move %d", i)
+		}
+		if err := g.MakeMove(*move, color); err != nil {
+			t.Fatalf("MCTS returned invalid move %v: %v", *move, err)
+		}
+	}
+}
+
+func TestMCTSCapturesLargeGroup(t *testing.T) {
+	g := game.NewGame(9)
+
+	// A four-stone white group in the center with a single liberty at
+	// (4,5).
+	for _, p := range []game.Point{{X: 3, Y: 3}, {X: 4, Y: 3}, {X: 3, Y: 4}, {X: 4, Y: 4}} {
+		g.Board.SetStone(p, game.White)
+	}
+	for _, p := range []game.Point{
+		{X: 3, Y: 2}, {X: 4, Y: 2}, {X: 2, Y: 3}, {X: 5, Y: 3},
+		{X: 2, Y: 4}, {X: 5, Y: 4}, {X: 3, Y: 5},
+	} {
+		g.Board.SetStone(p, game.Black)
+	}
+
+	ai := game.NewAI(game.Black, "mcts")
+	ai.Playouts = 2000
+	move := ai.GetMove(g)
+	if move == nil || *move != (game.Point{X: 4, Y: 5}) {
+		t.Errorf("Expected capture at (4,5), got %v", move)
+	}
+}
+
+func TestMCTSMoveTime(t *testing.T) {
+	g := game.NewGame(9)
+	ai := game.NewAI(game.Black, "mcts")
+	ai.MoveTime = 100 * time.Millisecond
+
+	start := time.Now()
+	if move := ai.GetMove(g); move == nil {
+		t.Error("MCTS should return a move on an empty board")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Search took %v with a 100ms budget", elapsed)
+	}
+}
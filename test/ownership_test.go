@@ -0,0 +1,172 @@
+package test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+	"github.com/Prawal-Sharma/GoSim/pkg/gtp"
+)
+
+// settledGame has a pass-alive Black group on the left four columns and
+// a pass-alive White group on the right three, so every playout ends at
+// once with the same owners. It is scored by area without komi.
+func settledGame() *game.Game {
+	g := game.NewGame(7)
+	g.Board = boardFromRows(
+		".X.XO.O",
+		"XXXXOOO",
+		"XXXXOOO",
+		"XXXXOOO",
+		"XXXXOOO",
+		"XXXXOOO",
+		".X.XO.O",
+	)
+	g.Rules.ScoringMethod = game.ChineseScoring
+	g.Rules.Komi = 0
+	return g
+}
+
+// invadedGame is splitBoard with a White stone in Black's area and a
+// Black stone in White's.
+func invadedGame() *game.Game {
+	g := splitBoard()
+	g.Board.SetStone(game.Point{X: 1, Y: 1}, game.White)
+	g.Board.SetStone(game.Point{X: 7, Y: 6}, game.Black)
+	return g
+}
+
+func TestOwnershipSettledPosition(t *testing.T) {
+	g := settledGame()
+	o := game.EstimateOwnership(context.Background(), g, 20, rand.New(rand.NewSource(1)))
+
+	if o.Playouts != 20 {
+		t.Errorf("Expected 20 playouts, got %d", o.Playouts)
+	}
+	for x := 0; x < 7; x++ {
+		for y := 0; y < 7; y++ {
+			expected := 1.0
+			if x >= 4 {
+				expected = -1
+			}
+			if v := o.At(game.Point{X: x, Y: y}); v != expected {
+				t.Errorf("Expected ownership %v at (%d,%d), got %v", expected, x, y, v)
+			}
+		}
+	}
+
+	score := o.Score(g)
+	if score.Black != 28 || score.White != 21 || score.GetResult() != "B+7" {
+		t.Errorf("Expected B+7 with 28 to 21, got %s with %.1f to %.1f", score.GetResult(), score.Black, score.White)
+	}
+}
+
+func TestOwnershipSuggestsDeadStones(t *testing.T) {
+	g := invadedGame()
+	o := game.EstimateOwnership(context.Background(), g, 0, rand.New(rand.NewSource(1)))
+
+	if o.Playouts != game.DefaultOwnershipPlayouts {
+		t.Errorf("Expected %d playouts, got %d", game.DefaultOwnershipPlayouts, o.Playouts)
+	}
+	if o.Owner(game.Point{X: 1, Y: 1}) != game.Black || o.Owner(game.Point{X: 7, Y: 6}) != game.White {
+		t.Error("Expected the invading stones' points to belong to the defenders")
+	}
+
+	dead := o.DeadStones(g.Board)
+	if len(dead) != 2 || !dead[game.Point{X: 1, Y: 1}] || !dead[game.Point{X: 7, Y: 6}] {
+		t.Errorf("Expected both invading stones dead, got %v", dead)
+	}
+
+	// With both removed each side has 36 points, so komi decides.
+	if result := o.Score(g).GetResult(); result != "W+6.5" {
+		t.Errorf("Expected W+6.5, got %s", result)
+	}
+}
+
+func TestOwnershipStopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	o := game.EstimateOwnership(ctx, game.NewGame(9), 100, rand.New(rand.NewSource(1)))
+	if o.Playouts != 1 {
+		t.Errorf("Expected a single playout after cancellation, got %d", o.Playouts)
+	}
+}
+
+func TestScoringStartsFromSuggestedDeadStones(t *testing.T) {
+	g := invadedGame()
+	o := game.EstimateOwnership(context.Background(), g, 0, rand.New(rand.NewSource(1)))
+
+	if err := g.SuggestDeadStones(o); err != game.ErrNotScoring {
+		t.Errorf("Expected ErrNotScoring before the scoring phase, got %v", err)
+	}
+
+	g.AgreeDeadStones = true
+	g.Pass(game.Black)
+	g.Pass(game.White)
+	if err := g.SuggestDeadStones(o); err != nil {
+		t.Fatal(err)
+	}
+	if list := g.DeadStoneList(); len(list) != 2 {
+		t.Errorf("Expected the two suggested stones marked dead, got %v", list)
+	}
+	if result := g.Score().GetResult(); result != "W+6.5" {
+		t.Errorf("Expected W+6.5 with the suggestion, got %s", result)
+	}
+}
+
+func TestAIResignsWhenFarBehind(t *testing.T) {
+	g := settledGame()
+
+	ai := game.NewAI(game.White, "random", game.WithSeed(1))
+	if ai.ShouldResign(context.Background(), g, game.White) {
+		t.Error("Expected an AI without a resign margin never to resign")
+	}
+
+	ai.ResignMargin = 5
+	if !ai.ShouldResign(context.Background(), g, game.White) {
+		t.Error("Expected White to resign 7 points behind with a margin of 5")
+	}
+	if ai.ShouldResign(context.Background(), g, game.Black) {
+		t.Error("Expected Black not to resign while ahead")
+	}
+
+	ai = game.NewAI(game.White, "random", game.WithSeed(1), game.WithResignMargin(10))
+	if ai.ShouldResign(context.Background(), g, game.White) {
+		t.Error("Expected White not to resign 7 points behind with a margin of 10")
+	}
+}
+
+func TestGTPGenmoveResigns(t *testing.T) {
+	engine := gtp.NewEngine("random")
+	engine.Seed = 1
+	engine.ResignMargin = 5
+
+	execute := func(command string, args ...string) string {
+		t.Helper()
+		response, err := engine.Execute(command, args)
+		if err != nil {
+			t.Fatalf("%s %v: %v", command, args, err)
+		}
+		return response
+	}
+	execute("boardsize", "7")
+	execute("komi", "0")
+	board := settledGame().Board
+	for x := 0; x < 7; x++ {
+		for y := 0; y < 7; y++ {
+			p := game.Point{X: x, Y: y}
+			if color := board.GetColor(p); color != game.Empty {
+				execute("play", color.String(), gtp.FormatVertex(&p, 7))
+			}
+		}
+	}
+
+	if response := execute("genmove", "white"); response != "resign" {
+		t.Errorf("Expected White to resign, got %q", response)
+	}
+	if response := execute("genmove", "black"); response != "pass" {
+		t.Errorf("Expected Black to pass with nothing left to play, got %q", response)
+	}
+}
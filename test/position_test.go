@@ -0,0 +1,159 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+func TestDiagramRoundTrip(t *testing.T) {
+	board := boardFromRows(
+		"X.O..",
+		".XO..",
+		".XO.O",
+		"..XO.",
+		".....",
+	)
+
+	diagram := board.Diagram()
+	if expected := "X.O..\n.XO..\n.XO.O\n..XO.\n.....\n"; diagram != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, diagram)
+	}
+
+	for _, text := range []string{diagram, board.String()} {
+		parsed, err := game.ParseDiagram(text)
+		if err != nil {
+			t.Fatalf("ParseDiagram(%q): %v", text, err)
+		}
+		if parsed.Hash != board.Hash || parsed.Diagram() != diagram {
+			t.Errorf("Expected ParseDiagram to restore\n%s\ngot\n%s", diagram, parsed.Diagram())
+		}
+	}
+}
+
+func TestParseDiagramRectangular(t *testing.T) {
+	// GTP showboard output, with + on the star points.
+	board, err := game.ParseDiagram(`
+		   A B C D E F G
+		 3 . X . + . . O 3
+		 2 . . . . . . . 2
+		 1 X . . . . O . 1
+		   A B C D E F G
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.Width != 7 || board.Height != 3 {
+		t.Fatalf("Expected 7x3, got %dx%d", board.Width, board.Height)
+	}
+	if board.GetColor(game.Point{X: 1, Y: 0}) != game.Black || board.GetColor(game.Point{X: 5, Y: 2}) != game.White {
+		t.Error("Expected the stones at B3 and F1")
+	}
+
+	wide := game.NewRectangularBoard(30, 2)
+	wide.SetStone(game.Point{X: 29, Y: 1}, game.White)
+	parsed, err := game.ParseDiagram(wide.String())
+	if err != nil || parsed.Width != 30 || parsed.GetColor(game.Point{X: 29, Y: 1}) != game.White {
+		t.Errorf("Expected a 30-wide board to round-trip, got %v", err)
+	}
+}
+
+func TestParseDiagramErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"X..\n..",
+		"X.Z\n...",
+		"X",
+		" 3 ...\n 1 ...\n 2 ...",
+	}
+	for _, text := range tests {
+		if _, err := game.ParseDiagram(text); err == nil {
+			t.Errorf("Expected an error for %q", text)
+		}
+	}
+}
+
+func TestPositionRoundTrip(t *testing.T) {
+	g := gameFromDiagram(t, koDiagram)
+	if err := g.MakeMove(game.Point{X: 5, Y: 4}, game.Black); err != nil {
+		t.Fatal(err)
+	}
+
+	position := g.Position()
+	if expected := "9/9/9/4XO3/3X1XO2/4XO3/9/9/9 w E5 1 0"; position != expected {
+		t.Fatalf("Expected %q, got %q", expected, position)
+	}
+
+	parsed, err := game.ParsePosition(position)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Board.Hash != g.Board.Hash || parsed.CurrentTurn != game.White || parsed.Board.Captures[game.Black] != 1 {
+		t.Error("Expected the parsed position to match the game")
+	}
+	if parsed.Position() != position {
+		t.Errorf("Expected %q to round-trip, got %q", position, parsed.Position())
+	}
+
+	// The ko point carries over, so White cannot retake at once.
+	if err := parsed.MakeMove(game.Point{X: 4, Y: 4}, game.White); err != game.ErrKoViolation {
+		t.Errorf("Expected ErrKoViolation, got %v", err)
+	}
+
+	// Passing lifts the ko.
+	g.Pass(game.White)
+	if position := g.Position(); position != "9/9/9/4XO3/3X1XO2/4XO3/9/9/9 b - 1 0" {
+		t.Errorf("Expected no ko after a pass, got %q", position)
+	}
+}
+
+func TestParsePositionRectangular(t *testing.T) {
+	g, err := game.ParsePosition("X1O/3/2X/3/O2 w - 3 12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Board.Width != 3 || g.Board.Height != 5 {
+		t.Fatalf("Expected 3x5, got %dx%d", g.Board.Width, g.Board.Height)
+	}
+	if g.Board.GetColor(game.Point{X: 2, Y: 2}) != game.Black || g.Board.GetColor(game.Point{X: 0, Y: 4}) != game.White {
+		t.Error("Expected the stones at C3 and A1")
+	}
+	if g.Board.Captures[game.Black] != 3 || g.Board.Captures[game.White] != 12 {
+		t.Errorf("Expected captures 3 and 12, got %v", g.Board.Captures)
+	}
+
+	// Runs of more than 9 empty points take two digits.
+	wide := game.NewRectangularBoard(25, 2)
+	wide.SetStone(game.Point{X: 12, Y: 0}, game.Black)
+	g, _ = game.NewRectangularGame(25, 2)
+	g.Board = wide
+	if position := g.Position(); position != "12X12/25 b - 0 0" {
+		t.Errorf("Expected two-digit runs, got %q", position)
+	}
+}
+
+func TestParsePositionErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"3/3/3 b - 0",
+		"3/3/3 x - 0 0",
+		"3/3/2 b - 0 0",
+		"3/3/3Z b - 0 0",
+		"3/3/3 b D4 0 0",
+		"X2/3/3 b A3 0 0",
+		"3/3/3 b - -1 0",
+		"0/0 b - 0 0",
+		"1/1 b - 0 0",
+	}
+	for _, s := range tests {
+		_, err := game.ParsePosition(s)
+		if err == nil {
+			t.Errorf("Expected an error for %q", s)
+			continue
+		}
+		if !errors.Is(err, game.ErrInvalidPosition) && err != game.ErrInvalidBoardSize {
+			t.Errorf("Expected ErrInvalidPosition for %q, got %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+func TestLookupRuleset(t *testing.T) {
+	tests := map[string]string{
+		"Japanese":     "japanese",
+		"CHINESE":      "chinese",
+		"aga":          "aga",
+		"NZ":           "new_zealand",
+		"New Zealand":  "new_zealand",
+		"Tromp-Taylor": "tromp-taylor",
+		"tromp_taylor": "tromp-taylor",
+		"GOE":          "ing",
+	}
+	for name, expected := range tests {
+		rs, err := game.LookupRuleset(name)
+		if err != nil || rs.Name != expected {
+			t.Errorf("LookupRuleset(%q) = %q, %v; expected %q", name, rs.Name, err, expected)
+		}
+	}
+
+	if _, err := game.LookupRuleset("klingon"); err != game.ErrUnknownRules {
+		t.Errorf("Expected ErrUnknownRules, got %v", err)
+	}
+	if _, err := game.NewGameWithRuleset(9, ""); err != game.ErrUnknownRules {
+		t.Errorf("Expected ErrUnknownRules for an empty name, got %v", err)
+	}
+	if _, err := game.NewGameWithRuleset(0, "japanese"); err != game.ErrInvalidBoardSize {
+		t.Errorf("Expected ErrInvalidBoardSize for a size of 0, got %v", err)
+	}
+	if len(game.Rulesets()) != 6 {
+		t.Errorf("Expected 6 presets, got %d", len(game.Rulesets()))
+	}
+}
+
+func TestRulesetPresetValues(t *testing.T) {
+	g, err := game.NewGameWithRuleset(19, "chinese")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := g.Rules
+	if rules.KoRule != game.PositionalSuperko || rules.ScoringMethod != game.ChineseScoring || rules.Komi != 7.5 || rules.HandicapCompensation != game.ChineseCompensation {
+		t.Errorf("Unexpected Chinese rules %+v", rules)
+	}
+	if info := g.GetGameInfo(); info["ruleset"] != "chinese" {
+		t.Errorf("Expected the ruleset in game info, got %v", info["ruleset"])
+	}
+}
+
+// suicideSetup gives Black a two-stone chain in the corner whose last
+// liberty, (0,2), is surrounded by White.
+func suicideSetup(t *testing.T, ruleset string) *game.Game {
+	t.Helper()
+	g, err := game.NewGameWithRuleset(5, ruleset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []game.Point{{X: 0, Y: 0}, {X: 0, Y: 1}} {
+		g.Board.SetStone(p, game.Black)
+	}
+	for _, p := range []game.Point{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}, {X: 0, Y: 3}} {
+		g.Board.SetStone(p, game.White)
+	}
+	return g
+}
+
+func TestRulesetSuicide(t *testing.T) {
+	g := suicideSetup(t, "japanese")
+	if err := g.MakeMove(game.Point{X: 0, Y: 2}, game.Black); err != game.ErrSuicideMove {
+		t.Errorf("Expected ErrSuicideMove under Japanese rules, got %v", err)
+	}
+
+	g = suicideSetup(t, "nz")
+	if err := g.MakeMove(game.Point{X: 0, Y: 2}, game.Black); err != nil {
+		t.Fatalf("Expected suicide to be legal under NZ rules, got %v", err)
+	}
+	for y := 0; y < 3; y++ {
+		if g.Board.GetColor(game.Point{X: 0, Y: y}) != game.Empty {
+			t.Errorf("Expected (0,%d) to be emptied by suicide", y)
+		}
+	}
+	if g.Board.Captures[game.White] != 3 {
+		t.Errorf("Expected White to take 3 prisoners, got %d", g.Board.Captures[game.White])
+	}
+	if g.Board.KoPoint != nil {
+		t.Errorf("Expected no ko after suicide, got %v", g.Board.KoPoint)
+	}
+
+	// A single-stone suicide would repeat the position, which positional
+	// superko forbids.
+	g, _ = game.NewGameWithRuleset(5, "tromp-taylor")
+	g.Board.SetStone(game.Point{X: 3, Y: 4}, game.White)
+	g.Board.SetStone(game.Point{X: 4, Y: 3}, game.White)
+	if err := g.MakeMove(game.Point{X: 4, Y: 4}, game.Black); err != game.ErrSuperkoViolation {
+		t.Errorf("Expected ErrSuperkoViolation for a single-stone suicide, got %v", err)
+	}
+}
+
+func TestAGAEndsWhenWhitePassesLast(t *testing.T) {
+	g, err := game.NewGameWithRuleset(9, "aga")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.MakeMove(game.Point{X: 4, Y: 4}, game.Black); err != nil {
+		t.Fatal(err)
+	}
+
+	g.Pass(game.White)
+	g.Pass(game.Black)
+	if g.IsOver {
+		t.Fatal("Expected the game to continue until White passes last")
+	}
+	g.Pass(game.White)
+	if !g.IsOver {
+		t.Fatal("Expected the game to end when White passes last")
+	}
+}
+
+func TestPassStonesChangeTerritoryScore(t *testing.T) {
+	for _, passStones := range []bool{false, true} {
+		g := game.NewGame(9)
+		g.Rules.ScoringMethod = game.JapaneseScoring
+		g.Rules.PassStones = passStones
+		g.Rules.Komi = 79.5
+
+		// White passes twice and Black once, so pass stones leave Black a
+		// prisoner ahead: 79 points of territory plus 2 against 1 + 79.5.
+		g.MakeMove(game.Point{X: 4, Y: 4}, game.Black)
+		g.Pass(game.White)
+		g.MakeMove(game.Point{X: 2, Y: 2}, game.Black)
+		g.Pass(game.White)
+		g.Pass(game.Black)
+		if !g.IsOver || g.Winner == nil {
+			t.Fatal("Expected the game to end after the passes")
+		}
+
+		want := game.White
+		if passStones {
+			want = game.Black
+		}
+		if *g.Winner != want {
+			t.Errorf("PassStones %v: expected %v to win, got %v (%v)", passStones, want, *g.Winner, g.Score().Summary())
+		}
+	}
+}
+
+func TestRulesetSGF(t *testing.T) {
+	g, err := game.NewGameWithRuleset(9, "new_zealand")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := game.NewRecordFromGame(g).SGF()
+	if !strings.Contains(text, "RU[NZ]") || !strings.Contains(text, "KM[7]") {
+		t.Errorf("Expected RU[NZ] and KM[7] in %s", text)
+	}
+
+	records, err := game.LoadSGF(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := records[0].Game()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Rules.Name != "new_zealand" || !loaded.Rules.AllowSuicide {
+		t.Errorf("Expected New Zealand rules after loading, got %+v", loaded.Rules)
+	}
+}
+
+func TestGTPKGSRules(t *testing.T) {
+	suicide := "boardsize 5\nclear_board\n%s" +
+		"play b A5\nplay w B5\nplay b A4\nplay w B4\nplay b E1\nplay w B3\nplay b E2\nplay w A2\nplay b A3\n"
+
+	responses := runGTP(t, strings.Replace(suicide, "%s", "kgs-rules new_zealand\n", 1))
+	if responses[2] != "= " {
+		t.Fatalf("Expected kgs-rules to succeed, got %q", responses[2])
+	}
+	if last := responses[len(responses)-1]; last != "= " {
+		t.Errorf("Expected suicide to be legal under NZ rules, got %q", last)
+	}
+
+	responses = runGTP(t, strings.Replace(suicide, "%s", "", 1))
+	if last := responses[len(responses)-1]; last != "? illegal move" {
+		t.Errorf("Expected suicide to be illegal by default, got %q", last)
+	}
+
+	responses = runGTP(t, "kgs-rules klingon\n")
+	if responses[0] != "? unknown rules" {
+		t.Errorf("Expected unknown rules, got %q", responses[0])
+	}
+}
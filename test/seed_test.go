@@ -0,0 +1,100 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+	"github.com/Prawal-Sharma/GoSim/pkg/gtp"
+)
+
+// playSeeded plays a short self-play game on 9x9 and returns its moves.
+func playSeeded(difficulty string, seed int64) []*game.Point {
+	g := game.NewGame(9)
+	players := map[game.Color]*game.AI{
+		game.Black: game.NewAI(game.Black, difficulty, game.WithSeed(seed), game.WithPlayouts(100)),
+		game.White: game.NewAI(game.White, difficulty, game.WithSeed(seed+1), game.WithPlayouts(100)),
+	}
+
+	moves := []*game.Point{}
+	for i := 0; i < 8; i++ {
+		color := g.CurrentTurn
+		move := players[color].GetMove(g)
+		moves = append(moves, move)
+		if move == nil {
+			g.Pass(color)
+		} else {
+			g.MakeMove(*move, color)
+		}
+	}
+	return moves
+}
+
+func sameMoves(a, b []*game.Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if (a[i] == nil) != (b[i] == nil) || (a[i] != nil && *a[i] != *b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSeededAIIsReproducible(t *testing.T) {
+	for _, difficulty := range []string{"random", "medium", "mcts"} {
+		first := playSeeded(difficulty, 42)
+		if !sameMoves(first, playSeeded(difficulty, 42)) {
+			t.Errorf("%s: the same seed should give the same game", difficulty)
+		}
+
+		differs := false
+		for seed := int64(1); seed <= 3 && !differs; seed++ {
+			differs = !sameMoves(first, playSeeded(difficulty, 42+seed*10))
+		}
+		if !differs {
+			t.Errorf("%s: different seeds should give different games", difficulty)
+		}
+	}
+}
+
+func TestAISeed(t *testing.T) {
+	ai := game.NewAI(game.Black, "random")
+	replay := game.NewAI(game.Black, "random", game.WithSeed(ai.Seed()))
+
+	g := game.NewGame(9)
+	if a, b := ai.GetMove(g), replay.GetMove(g); *a != *b {
+		t.Errorf("Replaying with Seed() should repeat the move, got %v and %v", *a, *b)
+	}
+
+	engine, _ := game.NewEngine("random")
+	seeder, ok := engine.(game.Seeder)
+	if !ok {
+		t.Fatal("AI engines should implement Seeder")
+	}
+	seeder.SetSeed(7)
+	if seeder.Seed() != 7 {
+		t.Errorf("Expected seed 7, got %d", seeder.Seed())
+	}
+}
+
+func TestGTPSeed(t *testing.T) {
+	run := func() string {
+		var out bytes.Buffer
+		engine := gtp.NewEngine("medium")
+		engine.Seed = 99
+		engine.Run(strings.NewReader("boardsize 9\ngenmove b\ngenmove w\nclear_board\ngenmove b\n"), &out)
+		return out.String()
+	}
+
+	first := run()
+	if first != run() {
+		t.Errorf("A seeded GTP engine should repeat its moves")
+	}
+	responses := strings.Split(first, "\n\n")
+	if responses[1] != responses[4] {
+		t.Errorf("clear_board should restart the seeded sequence: %q vs %q", responses[1], responses[4])
+	}
+}
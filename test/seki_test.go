@@ -0,0 +1,116 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+// boardFromRows builds a square board from rows of X (Black), O (White)
+// and . (empty), top row first.
+func boardFromRows(rows ...string) *game.Board {
+	b := game.NewBoard(len(rows))
+	for y, row := range rows {
+		for x, c := range row {
+			switch c {
+			case 'X':
+				b.SetStone(game.Point{X: x, Y: y}, game.Black)
+			case 'O':
+				b.SetStone(game.Point{X: x, Y: y}, game.White)
+			}
+		}
+	}
+	return b
+}
+
+func TestSekiWithoutEyes(t *testing.T) {
+	// Black's five stones and the White stone on the edge share two
+	// liberties; whoever fills one is captured.
+	board := boardFromRows(
+		".......",
+		".......",
+		".......",
+		".......",
+		"OOOOO..",
+		"XXXXO..",
+		".O.XO..",
+	)
+
+	sekis := game.FindSeki(board)
+	if len(sekis) != 1 {
+		t.Fatalf("Expected 1 seki, got %d", len(sekis))
+	}
+	if len(sekis[0].Stones) != 6 {
+		t.Errorf("Expected 6 stones in seki, got %v", sekis[0].Stones)
+	}
+	shared := sekis[0].Shared
+	if len(shared) != 2 || shared[0] != (game.Point{X: 0, Y: 6}) || shared[1] != (game.Point{X: 2, Y: 6}) {
+		t.Errorf("Expected shared liberties (0,6) and (2,6), got %v", shared)
+	}
+	if len(sekis[0].Eyes) != 0 {
+		t.Errorf("Expected no eyes, got %v", sekis[0].Eyes)
+	}
+
+	dead := game.MarkDeadStones(board)
+	for _, p := range sekis[0].Stones {
+		if dead.Stones[p] {
+			t.Errorf("Stone %v in seki was marked dead", p)
+		}
+	}
+}
+
+func TestSekiWithOneEyeEach(t *testing.T) {
+	// Each side has one eye in the corner and they share (2,2).
+	board := boardFromRows(
+		".XXOO",
+		"XXXOO",
+		"XX.OO",
+		"XXOOO",
+		"XXOO.",
+	)
+
+	sekis := game.FindSeki(board)
+	if len(sekis) != 1 {
+		t.Fatalf("Expected 1 seki, got %d", len(sekis))
+	}
+	eyes := sekis[0].Eyes
+	if len(eyes) != 2 || eyes[game.Point{X: 0, Y: 0}] != game.Black || eyes[game.Point{X: 4, Y: 4}] != game.White {
+		t.Errorf("Expected eyes at (0,0) for Black and (4,4) for White, got %v", eyes)
+	}
+	if dead := game.MarkDeadStones(board); len(dead.Stones) != 0 {
+		t.Errorf("Expected no dead stones, got %d", len(dead.Stones))
+	}
+
+	// Territory scoring counts the eyes as dame; area scoring counts them.
+	territory := game.CalculateScore(board, game.JapaneseScoring, 0)
+	if territory.Territory[game.Black] != 0 || territory.Territory[game.White] != 0 {
+		t.Errorf("Expected no territory in seki, got %v", territory.Territory)
+	}
+	area := game.CalculateScore(board, game.ChineseScoring, 0)
+	if area.Black != 12 || area.White != 12 {
+		t.Errorf("Expected an area count of 12 each, got B %.1f W %.1f", area.Black, area.White)
+	}
+}
+
+func TestNoSekiForDeadOrLiveGroups(t *testing.T) {
+	g := splitBoard()
+	g.Board.SetStone(game.Point{X: 1, Y: 1}, game.White)
+	if sekis := game.FindSeki(g.Board); len(sekis) != 0 {
+		t.Errorf("Expected no seki for a stone inside enemy territory, got %v", sekis)
+	}
+
+	// A Black group in atari is not in seki: White captures by filling
+	// the shared liberty.
+	board := boardFromRows(
+		"XXO....",
+		"O......",
+		".......",
+		".......",
+		".......",
+		".......",
+		".......",
+	)
+	if sekis := game.FindSeki(board); len(sekis) != 0 {
+		t.Errorf("Expected no seki for a group in atari, got %v", sekis)
+	}
+}
@@ -0,0 +1,271 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+	"github.com/Prawal-Sharma/GoSim/pkg/sgf"
+)
+
+func TestSGFParseVariations(t *testing.T) {
+	collection, err := sgf.ParseString("(;GM[1]SZ[9];B[ee](;W[cc];B[gg])(;W[gc]))")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(collection) != 1 {
+		t.Fatalf("Expected 1 game, got %d", len(collection))
+	}
+
+	first := collection[0].Children[0]
+	if first.Get("B") != "ee" {
+		t.Errorf("Expected B[ee], got B[%s]", first.Get("B"))
+	}
+	if len(first.Children) != 2 {
+		t.Fatalf("Expected 2 variations, got %d", len(first.Children))
+	}
+	if first.Children[1].Get("W") != "gc" {
+		t.Errorf("Expected second variation W[gc], got W[%s]", first.Children[1].Get("W"))
+	}
+	if len(collection[0].MainLine()) != 4 {
+		t.Errorf("Expected main line of 4 nodes, got %d", len(collection[0].MainLine()))
+	}
+}
+
+func TestSGFParseEscapesAndCollection(t *testing.T) {
+	input := "header text (;GM[1]C[a \\] b \\\\ c\\\nd]LB[dd:x\\:y])\n(;GM[1]PlayerBlack[Alice])"
+
+	collection, err := sgf.ParseString(input)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(collection) != 2 {
+		t.Fatalf("Expected 2 games, got %d", len(collection))
+	}
+
+	if c := collection[0].Get("C"); c != "a ] b \\ cd" {
+		t.Errorf("Unexpected comment %q", c)
+	}
+	if point, label, _ := sgf.SplitCompose(collection[0].Get("LB")); point != "dd" || label != "x:y" {
+		t.Errorf("Unexpected label %q:%q", point, label)
+	}
+	if text := collection[0].String(); !strings.Contains(text, `LB[dd:x\:y]`) {
+		t.Errorf("Expected the escaped colon to be written back, got %s", text)
+	}
+	if collection[1].Get("PB") != "Alice" {
+		t.Errorf("Long FF[3] identifier should read as PB, got %v", collection[1].Properties)
+	}
+}
+
+func TestSGFParseErrors(t *testing.T) {
+	inputs := []string{"", "(;B[aa]", "(;B[aa)", "(;B)", "()"}
+	for _, input := range inputs {
+		if _, err := sgf.ParseString(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestLoadSGFRecord(t *testing.T) {
+	input := `(;FF[4]GM[1]SZ[9]KM[6.5]HA[2]RU[Japanese]PB[Black]PW[White]RE[W+R]
+AB[cc][gg]AW[ee]
+;AE[ee]PL[W];W[dc];B[cd];W[ec];B[dd]C[Black to play]
+(;W[db];B[ed]TR[ed])
+(;W[]))`
+
+	records, err := game.LoadSGF(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("LoadSGF failed: %v", err)
+	}
+	record := records[0]
+
+	if record.Width != 9 || record.Komi != 6.5 || record.Handicap != 2 || record.Rules != "Japanese" {
+		t.Errorf("Unexpected metadata: %+v", record)
+	}
+
+	g, err := record.Game()
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if g.Board.GetColor(game.Point{X: 2, Y: 2}) != game.Black || g.Board.GetColor(game.Point{X: 6, Y: 6}) != game.Black {
+		t.Error("Handicap stones should be placed by AB")
+	}
+	if g.Board.GetColor(game.Point{X: 4, Y: 4}) != game.Empty {
+		t.Error("AE should clear the stone placed by AW")
+	}
+	if g.Board.GetColor(game.Point{X: 4, Y: 3}) != game.Black {
+		t.Error("Main line should end with B[ed]")
+	}
+
+	// Replay the pass variation instead of the main line.
+	variation := record.Root.MainLine()[5].Children[1]
+	g, err = record.GameAt(variation)
+	if err != nil {
+		t.Fatalf("Replay of variation failed: %v", err)
+	}
+	if !g.Passed[game.White] || g.CurrentTurn != game.Black {
+		t.Error("Variation should end with a White pass")
+	}
+	if g.Board.GetColor(game.Point{X: 3, Y: 1}) != game.Empty {
+		t.Error("Main line move should not be played in the variation")
+	}
+}
+
+func TestLoadSGFSetupAfterMove(t *testing.T) {
+	records, err := game.LoadSGF(strings.NewReader("(;SZ[9];B[aa];AE[aa]AB[bb];W[cc])"))
+	if err != nil {
+		t.Fatalf("LoadSGF failed: %v", err)
+	}
+	g, err := records[0].Game()
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if g.Board.GetColor(game.Point{X: 0, Y: 0}) != game.Empty || g.Board.GetColor(game.Point{X: 1, Y: 1}) != game.Black {
+		t.Error("Setup after the first move should change the stones")
+	}
+
+	// The setup is written back as a node of its own.
+	sgfText := game.NewRecordFromGame(g).SGF()
+	if !strings.Contains(sgfText, ";B[aa];AE[aa]AB[bb];W[cc]") {
+		t.Errorf("Expected the setup node in the record, got %s", sgfText)
+	}
+
+	// Undo takes the setup back like a move.
+	if !g.Undo() || !g.Undo() {
+		t.Fatal("Undo failed")
+	}
+	if g.Board.GetColor(game.Point{X: 0, Y: 0}) != game.Black || g.Board.GetColor(game.Point{X: 1, Y: 1}) != game.Empty {
+		t.Error("Undoing the setup should restore the stones")
+	}
+	if g.CurrentTurn != game.White || g.MoveCount != 1 {
+		t.Errorf("Expected White to move after 1 move, got %v after %d", g.CurrentTurn, g.MoveCount)
+	}
+	if !g.Redo() || g.Board.GetColor(game.Point{X: 1, Y: 1}) != game.Black {
+		t.Error("Redo should set the stones up again")
+	}
+}
+
+func TestLoadSGFCapture(t *testing.T) {
+	records, err := game.LoadSGF(strings.NewReader("(;SZ[9];B[ba];W[aa];B[ab])"))
+	if err != nil {
+		t.Fatalf("LoadSGF failed: %v", err)
+	}
+
+	g, err := records[0].Game()
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if g.Board.GetColor(game.Point{X: 0, Y: 0}) != game.Empty || g.Board.Captures[game.Black] != 1 {
+		t.Error("W[aa] should be captured")
+	}
+}
+
+func TestLoadSGFRectangular(t *testing.T) {
+	records, err := game.LoadSGF(strings.NewReader("(;SZ[9:5])"))
+	if err != nil {
+		t.Fatalf("LoadSGF failed: %v", err)
+	}
+	if records[0].Width != 9 || records[0].Height != 5 {
+		t.Errorf("Expected 9x5, got %dx%d", records[0].Width, records[0].Height)
+	}
+}
+
+func TestSGFWriteRoundTrip(t *testing.T) {
+	input := "(;FF[4]GM[1]SZ[9]C[escape \\] and \\\\ here];B[ee](;W[cc]LB[dd:a:b];B[gg])(;W[gc]TR[aa][bb]))"
+
+	collection, err := sgf.ParseString(input)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	reparsed, err := sgf.ParseString(collection.String())
+	if err != nil {
+		t.Fatalf("Parse of written SGF failed: %v", err)
+	}
+	if reparsed.String() != collection.String() {
+		t.Errorf("Round trip changed the SGF:\n%s\n%s", collection.String(), reparsed.String())
+	}
+	if c := reparsed[0].Get("C"); c != "escape ] and \\ here" {
+		t.Errorf("Unexpected comment after round trip: %q", c)
+	}
+	if lb := reparsed[0].Children[0].Children[0].Get("LB"); lb != "dd:a:b" {
+		t.Errorf("Unexpected label after round trip: %q", lb)
+	}
+}
+
+func TestRecordFromGame(t *testing.T) {
+	g := game.NewGame(9)
+	g.Board.SetStone(game.Point{X: 2, Y: 2}, game.Black)
+	g.CurrentTurn = game.White
+
+	g.Pass(game.White)
+	g.MakeMove(game.Point{X: 4, Y: 4}, game.Black)
+	g.MakeMove(game.Point{X: 3, Y: 4}, game.White)
+
+	record := game.NewRecordFromGame(g)
+	record.Komi = 0.5
+	record.Handicap = 2
+	record.Rules = "Japanese"
+	record.BlackPlayer = "Alice"
+	record.WhitePlayer = "Bob"
+	record.Result = "B+0.5"
+	record.Date = "2024-12-19"
+	record.TimeLimit = 600
+	record.Overtime = "5x30 byo-yomi"
+
+	last := record.LastNode()
+	last.Set("C", "Game over")
+	record.Mark(last, "TR", game.Point{X: 4, Y: 4})
+	record.Label(last, game.Point{X: 0, Y: 0}, "A")
+	record.Label(last, game.Point{X: 1, Y: 0}, `3:1 \ ]`)
+	record.MarkTerritory(last, map[game.Point]game.Color{{X: 8, Y: 8}: game.Black, {X: 0, Y: 8}: game.White})
+
+	variation := record.AddMove(record.Root.MainLine()[2], game.White, &game.Point{X: 5, Y: 5})
+	variation.Set("C", "Alternative")
+
+	text := record.SGF()
+	for _, expected := range []string{"KM[0.5]", "HA[2]", "RU[Japanese]", "PB[Alice]", "RE[B+0.5]", "TM[600]", "AB[cc]", "PL[W]", ";W[]", "TB[ii]", "TW[ai]", `LB[aa:A][ba:3\:1 \\ \]]`} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %s in %s", expected, text)
+		}
+	}
+
+	records, err := game.LoadSGF(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("LoadSGF failed: %v", err)
+	}
+	loaded := records[0]
+
+	if loaded.Komi != 0.5 || loaded.Handicap != 2 || loaded.WhitePlayer != "Bob" || loaded.Overtime != "5x30 byo-yomi" {
+		t.Errorf("Metadata lost in round trip: %+v", loaded)
+	}
+
+	end := loaded.LastNode()
+	if end.Get("C") != "Game over" {
+		t.Errorf("Comment lost in round trip")
+	}
+	if triangles, _ := loaded.Marked(end, "TR"); len(triangles) != 1 || triangles[0] != (game.Point{X: 4, Y: 4}) {
+		t.Errorf("Triangle lost in round trip: %v", triangles)
+	}
+	if labels, _ := loaded.Labels(end); labels[game.Point{X: 0, Y: 0}] != "A" || labels[game.Point{X: 1, Y: 0}] != `3:1 \ ]` {
+		t.Errorf("Label lost in round trip: %v", labels)
+	}
+
+	replayed, err := loaded.Game()
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	for x := 0; x < 9; x++ {
+		for y := 0; y < 9; y++ {
+			p := game.Point{X: x, Y: y}
+			if replayed.Board.GetColor(p) != g.Board.GetColor(p) {
+				t.Fatalf("Replayed position differs at %v", p)
+			}
+		}
+	}
+
+	alternative := loaded.Root.MainLine()[2].Children[1]
+	if alternative.Get("C") != "Alternative" || alternative.Get("W") != "ff" {
+		t.Errorf("Variation lost in round trip")
+	}
+}
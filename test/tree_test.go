@@ -0,0 +1,204 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+func TestUndoFirstMoveAndPasses(t *testing.T) {
+	g := game.NewGame(9)
+	if g.Undo() || g.Redo() {
+		t.Error("Expected nothing to undo or redo in a new game")
+	}
+
+	g.MakeMove(game.Point{X: 2, Y: 2}, game.Black)
+	g.Pass(game.White)
+	if g.MoveCount != 1 {
+		t.Fatalf("Expected 1 move, got %d", g.MoveCount)
+	}
+
+	// Undoing the pass leaves the move count alone.
+	g.Undo()
+	if g.MoveCount != 1 || g.CurrentTurn != game.White || g.Passed[game.White] {
+		t.Errorf("Expected White to move after 1 move, got %s after %d", g.CurrentTurn, g.MoveCount)
+	}
+
+	g.Undo()
+	if g.MoveCount != 0 || g.CurrentTurn != game.Black || g.Board.GetColor(game.Point{X: 2, Y: 2}) != game.Empty {
+		t.Error("Expected the first move to be undone")
+	}
+	if g.Board.LastMove != nil || len(g.Board.History) != 0 || g.Current != g.Tree {
+		t.Error("Expected the game back at the root")
+	}
+}
+
+func TestUndoRestoresPassFlags(t *testing.T) {
+	g := game.NewGame(9)
+	g.MakeMove(game.Point{X: 2, Y: 2}, game.Black)
+	g.Pass(game.White)
+	g.MakeMove(game.Point{X: 6, Y: 6}, game.Black)
+
+	// White passed last, so once Black's move is undone a Black pass ends
+	// the game.
+	g.Undo()
+	if !g.Passed[game.White] {
+		t.Fatal("Expected White's pass to count again after the undo")
+	}
+	g.Pass(game.Black)
+	if !g.IsOver || g.Winner == nil {
+		t.Fatal("Expected the second pass in a row to end the game")
+	}
+
+	winner := *g.Winner
+	g.Undo()
+	if g.IsOver || g.Winner != nil || g.CurrentTurn != game.Black {
+		t.Error("Expected the undo to reopen the game with Black to move")
+	}
+	if !g.Redo() || !g.IsOver || g.Winner == nil || *g.Winner != winner {
+		t.Error("Expected the redo to end the game again with the same winner")
+	}
+}
+
+func TestRedoRestoresKo(t *testing.T) {
+	g := gameFromDiagram(t, koDiagram)
+	g.MakeMove(game.Point{X: 5, Y: 4}, game.Black)
+	taken := g.Position()
+
+	g.Undo()
+	if g.Board.KoPoint != nil || g.Board.Captures[game.Black] != 0 {
+		t.Error("Expected the undo to clear the ko and the capture")
+	}
+	if !g.Redo() {
+		t.Fatal("Expected to redo the capture")
+	}
+	if g.Position() != taken {
+		t.Errorf("Expected %q after redo, got %q", taken, g.Position())
+	}
+	if err := g.MakeMove(game.Point{X: 4, Y: 4}, game.White); err != game.ErrKoViolation {
+		t.Errorf("Expected ErrKoViolation after redo, got %v", err)
+	}
+	if g.Redo() {
+		t.Error("Expected nothing to redo at the end of the line")
+	}
+}
+
+func TestVariations(t *testing.T) {
+	g := game.NewGame(9)
+	g.MakeMove(game.Point{X: 2, Y: 2}, game.Black)
+	g.MakeMove(game.Point{X: 6, Y: 6}, game.White)
+	g.MakeMove(game.Point{X: 2, Y: 6}, game.Black)
+	first := g.Current
+	firstPosition := g.Position()
+
+	// A different move after an undo starts a variation.
+	g.Undo()
+	g.Undo()
+	g.MakeMove(game.Point{X: 6, Y: 2}, game.White)
+	second := g.Current
+	secondPosition := g.Position()
+
+	branch := g.Tree.Children[0]
+	if len(g.Tree.Children) != 1 || len(branch.Children) != 2 {
+		t.Fatalf("Expected two variations after the first move, got %d", len(branch.Children))
+	}
+
+	// Playing a move already in the tree follows it.
+	g.Undo()
+	g.MakeMove(game.Point{X: 6, Y: 6}, game.White)
+	if g.Current != branch.Children[0] || len(branch.Children) != 2 {
+		t.Error("Expected the move to follow the existing variation")
+	}
+
+	if err := g.GoTo(first); err != nil {
+		t.Fatal(err)
+	}
+	if g.Position() != firstPosition || g.MoveCount != 3 || g.Current != first {
+		t.Errorf("Expected %q, got %q", firstPosition, g.Position())
+	}
+	if err := g.GoTo(second); err != nil {
+		t.Fatal(err)
+	}
+	if g.Position() != secondPosition || g.MoveCount != 2 {
+		t.Errorf("Expected %q, got %q", secondPosition, g.Position())
+	}
+	if err := g.GoTo(g.Tree); err != nil || g.MoveCount != 0 || g.Board.Hash != game.NewBoard(9).Hash {
+		t.Errorf("Expected the empty board at the root, got %v", err)
+	}
+
+	// Redo follows the line last visited.
+	g.Redo()
+	g.Redo()
+	if g.Current != second {
+		t.Error("Expected redo to return to the last variation visited")
+	}
+
+	if err := g.GoTo(game.NewGame(9).Tree); err != game.ErrNotInTree {
+		t.Errorf("Expected ErrNotInTree, got %v", err)
+	}
+}
+
+func TestGoToWithoutHistory(t *testing.T) {
+	g := game.NewGame(9)
+	g.MakeMove(game.Point{X: 2, Y: 2}, game.Black)
+	g.MakeMove(game.Point{X: 6, Y: 6}, game.White)
+
+	// A board whose history was replaced no longer reaches the root.
+	g.Board.History = nil
+	if err := g.GoTo(g.Tree); err != game.ErrHistoryLost {
+		t.Errorf("Expected ErrHistoryLost, got %v", err)
+	}
+}
+
+func TestGoToReplaysScoringPhase(t *testing.T) {
+	g := game.NewGame(9)
+	g.AgreeDeadStones = true
+	g.MakeMove(game.Point{X: 2, Y: 2}, game.Black)
+	g.Pass(game.White)
+	g.Pass(game.Black)
+	scoring := g.Current
+
+	g.ResumePlay(game.Black)
+	g.MakeMove(game.Point{X: 6, Y: 6}, game.White)
+	resumed := g.Current
+
+	if err := g.GoTo(scoring); err != nil {
+		t.Fatal(err)
+	}
+	if !g.Scoring {
+		t.Error("Expected the two passes to start scoring again")
+	}
+	if err := g.GoTo(resumed); err != nil {
+		t.Fatal(err)
+	}
+	if g.Scoring || g.Board.GetColor(game.Point{X: 6, Y: 6}) != game.White || g.CurrentTurn != game.Black {
+		t.Error("Expected play to resume with White's move")
+	}
+}
+
+func TestRecordKeepsVariations(t *testing.T) {
+	g := game.NewGame(9)
+	g.MakeMove(game.Point{X: 2, Y: 2}, game.Black)
+	g.MakeMove(game.Point{X: 6, Y: 6}, game.White)
+	g.Undo()
+	g.MakeMove(game.Point{X: 6, Y: 2}, game.White)
+	g.Pass(game.Black)
+
+	text := game.NewRecordFromGame(g).SGF()
+	if !strings.Contains(strings.ReplaceAll(text, "\n", ""), "(;W[gc];B[])(;W[gg]))") {
+		t.Errorf("Expected the current line first and the old one as a variation, got %s", text)
+	}
+
+	records, err := game.LoadSGF(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := records[0].Game()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Position() != g.Position() {
+		t.Errorf("Expected the main line to replay to %q, got %q", g.Position(), replayed.Position())
+	}
+}
@@ -0,0 +1,181 @@
+package test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/game"
+)
+
+func TestTrompTaylorScore(t *testing.T) {
+	g := splitBoard()
+	score := game.TrompTaylorScore(g.Board, 0.5)
+	if score.Black != 36 || score.White != 36.5 || score.GetResult() != "W+0.5" {
+		t.Errorf("Expected W+0.5 with 36 to 36.5, got %s with %.1f to %.1f", score.GetResult(), score.Black, score.White)
+	}
+
+	// Nothing is dead under Tromp-Taylor: the White stone makes Black's
+	// area reach White, so it is no longer Black's.
+	g.Board.SetStone(game.Point{X: 1, Y: 1}, game.White)
+	score = game.TrompTaylorScore(g.Board, 0.5)
+	if score.Black != 9 || score.White != 37.5 {
+		t.Errorf("Expected 9 to 37.5 with the invader alive, got %.1f to %.1f", score.Black, score.White)
+	}
+}
+
+func TestTrompTaylorGameMultiStoneSuicide(t *testing.T) {
+	tt := game.NewTrompTaylorGame(5, 5)
+	moves := []game.Point{
+		{X: 0, Y: 0}, {X: 2, Y: 0},
+		{X: 0, Y: 1}, {X: 1, Y: 1},
+		{X: 4, Y: 4}, {X: 0, Y: 2},
+	}
+	for _, p := range moves {
+		if err := tt.Play(p); err != nil {
+			t.Fatalf("Play(%v): %v", p, err)
+		}
+	}
+
+	// Black fills its last liberty and loses three stones.
+	if err := tt.Play(game.Point{X: 1, Y: 0}); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []game.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}} {
+		if tt.Grid[p.X][p.Y] != game.Empty {
+			t.Errorf("Expected %v to be emptied by suicide", p)
+		}
+	}
+
+	if err := tt.Play(game.Point{X: 4, Y: 4}); err != game.ErrPositionOccupied {
+		t.Errorf("Expected ErrPositionOccupied, got %v", err)
+	}
+	tt.Pass()
+	tt.Pass()
+	if !tt.IsOver() {
+		t.Error("Expected two passes to end the game")
+	}
+	if err := tt.Play(game.Point{X: 3, Y: 3}); err != game.ErrGameOver {
+		t.Errorf("Expected ErrGameOver, got %v", err)
+	}
+}
+
+func TestTrompTaylorRuleset(t *testing.T) {
+	g, err := game.NewGameWithRuleset(9, "tromp-taylor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Rules.ScoringMethod != game.TrompTaylorScoring {
+		t.Errorf("Expected Tromp-Taylor scoring, got %s", g.Rules.ScoringMethod)
+	}
+
+	for y := 0; y < 9; y++ {
+		g.Board.SetStone(game.Point{X: 3, Y: y}, game.Black)
+		g.Board.SetStone(game.Point{X: 5, Y: y}, game.White)
+	}
+	g.Board.SetStone(game.Point{X: 1, Y: 1}, game.White)
+
+	// Two passes end the game at once, and the invader is not removed.
+	g.AgreeDeadStones = true
+	g.Pass(game.Black)
+	g.Pass(game.White)
+	if g.Scoring || !g.IsOver {
+		t.Fatal("Expected two passes to end a Tromp-Taylor game without a scoring phase")
+	}
+	result := game.GetGameResult(g, g.Rules.ScoringMethod, g.Rules.Komi)
+	if len(result.DeadStones.Stones) != 0 {
+		t.Errorf("Expected no dead stones, got %v", result.DeadStones.Stones)
+	}
+	if result.Score.GetResult() != "W+35.5" {
+		t.Errorf("Expected W+35.5, got %s", result.Score.GetResult())
+	}
+}
+
+// TestTrompTaylorConformance plays random games with Game under the
+// tromp-taylor ruleset and with TrompTaylorGame side by side, checking
+// that they agree on every legal move, every resulting position and the
+// final score.
+func TestTrompTaylorConformance(t *testing.T) {
+	games := 200
+	if testing.Short() {
+		games = 20
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < games; i++ {
+		width, height := 4+i%4, 3+i%5
+		g, err := game.NewRectangularGame(width, height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.SetRuleset("tromp-taylor")
+		tt := game.NewTrompTaylorGame(width, height)
+
+		for move := 0; move < 4*width*height && !tt.IsOver(); move++ {
+			color := tt.ToMove
+			if g.CurrentTurn != color {
+				t.Fatalf("game %d move %d: Game has %s to move, oracle %s", i, move, g.CurrentTurn, color)
+			}
+
+			var legal []game.Point
+			for x := 0; x < width; x++ {
+				for y := 0; y < height; y++ {
+					p := game.Point{X: x, Y: y}
+					_, ttErr := tt.After(p)
+					gameErr := g.ValidateMove(p, color)
+					if (ttErr == nil) != (gameErr == nil) {
+						t.Fatalf("game %d move %d: %s at %v is %v for Game but %v for the oracle", i, move, color, p, gameErr, ttErr)
+					}
+					if ttErr == nil {
+						legal = append(legal, p)
+					}
+				}
+			}
+
+			if len(legal) == 0 || rng.Intn(20) == 0 {
+				g.Pass(color)
+				tt.Pass()
+				continue
+			}
+
+			p := legal[rng.Intn(len(legal))]
+			expected, _ := tt.After(p)
+
+			// Placing the stone and clearing the opponent's captures, then
+			// the player's own, is the Tromp-Taylor move.
+			board := g.Board.Clone()
+			board.SetStone(p, color)
+			board.CaptureDeadGroups(color)
+			board.CaptureDeadGroups(game.OpponentColor(color))
+			compareGrid(t, i, move, "CaptureDeadGroups", board.Grid, expected)
+
+			if err := g.MakeMove(p, color); err != nil {
+				t.Fatalf("game %d move %d: MakeMove(%v): %v", i, move, p, err)
+			}
+			tt.Play(p)
+			compareGrid(t, i, move, "MakeMove", g.Board.Grid, tt.Grid)
+		}
+
+		expected := tt.Score(g.Rules.Komi)
+		if score := g.Score(); score.Black != expected.Black || score.White != expected.White {
+			t.Fatalf("game %d: Game scores %.1f to %.1f, oracle %.1f to %.1f", i, score.Black, score.White, expected.Black, expected.White)
+		}
+		territory := g.Board.CountTerritory()
+		if territory[game.Black] != expected.Territory[game.Black] || territory[game.White] != expected.Territory[game.White] {
+			t.Fatalf("game %d: CountTerritory gives %v, oracle %v", i, territory, expected.Territory)
+		}
+		if area := game.CalculateScore(g.Board, game.ChineseScoring, g.Rules.Komi); area.Black != expected.Black || area.White != expected.White {
+			t.Fatalf("game %d: area scoring gives %.1f to %.1f, oracle %.1f to %.1f", i, area.Black, area.White, expected.Black, expected.White)
+		}
+	}
+}
+
+func compareGrid(t *testing.T, gameIndex, move int, name string, got, expected [][]game.Color) {
+	t.Helper()
+	for x := range expected {
+		for y := range expected[x] {
+			if got[x][y] != expected[x][y] {
+				t.Fatalf("game %d move %d: %s gives %s at (%d,%d), oracle %s", gameIndex, move, name, got[x][y], x, y, expected[x][y])
+			}
+		}
+	}
+}
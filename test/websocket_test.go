@@ -0,0 +1,604 @@
+package test
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Prawal-Sharma/GoSim/pkg/gtp"
+	"github.com/Prawal-Sharma/GoSim/pkg/websocket"
+	gorilla "github.com/gorilla/websocket"
+)
+
+// wsClient is a player connected to a test server.
+type wsClient struct {
+	conn     *gorilla.Conn
+	messages chan websocket.Message
+
+	// seq is the number of the last room message read.
+	seq int
+}
+
+func startServer(t *testing.T, grace time.Duration) (*websocket.Hub, string) {
+	t.Helper()
+	hub := websocket.NewHub()
+	hub.SetSeed(1)
+	hub.GracePeriod = grace
+	go hub.Run()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		websocket.HandleWebSocket(hub, w, r)
+	}))
+	t.Cleanup(server.Close)
+	return hub, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func dialClient(url string) (*wsClient, error) {
+	conn, _, err := gorilla.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	c := &wsClient{conn: conn, messages: make(chan websocket.Message, 1024)}
+	go func() {
+		defer close(c.messages)
+		for {
+			var msg websocket.Message
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			c.messages <- msg
+		}
+	}()
+	return c, nil
+}
+
+func (c *wsClient) send(msgType string, data map[string]interface{}) error {
+	return c.conn.WriteJSON(websocket.Message{Type: msgType, Data: data})
+}
+
+// waitFor reads up to the next message of type msgType, returning it and
+// the room events (moves, passes and undos) read on the way.
+func (c *wsClient) waitFor(msgType string) (websocket.Message, []string, error) {
+	var events []string
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case msg, ok := <-c.messages:
+			if !ok {
+				return msg, events, fmt.Errorf("connection closed waiting for %s", msgType)
+			}
+			if msg.Seq > 0 {
+				c.seq = msg.Seq
+			}
+			if msg.Type == msgType {
+				return msg, events, nil
+			}
+			switch msg.Type {
+			case "move_made", "pass", "undo":
+				events = append(events, fmt.Sprint(msg.Type, msg.Data["color"], msg.Data["x"], msg.Data["y"]))
+			}
+		case <-timeout:
+			return websocket.Message{}, events, fmt.Errorf("timed out waiting for %s", msgType)
+		}
+	}
+}
+
+// playRandomly sends moves, with the odd undo, to random points whether
+// or not it is the client's turn, then waits until the room has handled
+// them all.
+func (c *wsClient) playRandomly(rng *rand.Rand, commands int) ([]string, error) {
+	for i := 0; i < commands; i++ {
+		var err error
+		if rng.Intn(8) == 0 {
+			err = c.send("undo", nil)
+		} else {
+			err = c.send("make_move", map[string]interface{}{"x": rng.Intn(9), "y": rng.Intn(9)})
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := c.send("get_valid_moves", nil); err != nil {
+		return nil, err
+	}
+	_, events, err := c.waitFor("valid_moves")
+	return events, err
+}
+
+func (c *wsClient) createGame(data map[string]interface{}) (string, error) {
+	if err := c.send("create_game", data); err != nil {
+		return "", err
+	}
+	msg, _, err := c.waitFor("game_created")
+	if err != nil {
+		return "", err
+	}
+	return msg.Data["roomId"].(string), nil
+}
+
+// playTwoPlayerRoom has two clients play over each other in one room and
+// checks that both see the same moves in the same order.
+func playTwoPlayerRoom(url string, seed int64) error {
+	black, err := dialClient(url)
+	if err != nil {
+		return err
+	}
+	defer black.conn.Close()
+	roomID, err := black.createGame(map[string]interface{}{"boardSize": 9})
+	if err != nil {
+		return err
+	}
+
+	white, err := dialClient(url)
+	if err != nil {
+		return err
+	}
+	defer white.conn.Close()
+	if err := white.send("join_game", map[string]interface{}{"roomId": roomID}); err != nil {
+		return err
+	}
+	if _, _, err := white.waitFor("game_joined"); err != nil {
+		return err
+	}
+
+	players := []*wsClient{black, white}
+	events := make([][]string, len(players))
+	errs := make([]error, len(players))
+	var wg sync.WaitGroup
+	for i, player := range players {
+		wg.Add(1)
+		go func(i int, player *wsClient) {
+			defer wg.Done()
+			events[i], errs[i] = player.playRandomly(rand.New(rand.NewSource(seed+int64(i))), 40)
+		}(i, player)
+	}
+	wg.Wait()
+
+	// Every command has been handled now, so one more round trip brings
+	// the rest of the other player's moves.
+	for i, player := range players {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		if err := player.send("get_valid_moves", nil); err != nil {
+			return err
+		}
+		_, rest, err := player.waitFor("valid_moves")
+		if err != nil {
+			return err
+		}
+		events[i] = append(events[i], rest...)
+	}
+
+	if len(events[0]) == 0 {
+		return fmt.Errorf("room %s: no moves were played", roomID)
+	}
+	if strings.Join(events[0], "\n") != strings.Join(events[1], "\n") {
+		return fmt.Errorf("room %s: players saw different games:\n%v\n%v", roomID, events[0], events[1])
+	}
+	return nil
+}
+
+// playEngineRoom plays against an engine, whose moves come from the
+// room's goroutine.
+func playEngineRoom(url string, seed int64) error {
+	c, err := dialClient(url)
+	if err != nil {
+		return err
+	}
+	defer c.conn.Close()
+	if _, err := c.createGame(map[string]interface{}{"boardSize": 9, "engine": "random", "seed": seed}); err != nil {
+		return err
+	}
+	events, err := c.playRandomly(rand.New(rand.NewSource(seed)), 40)
+	if err == nil && len(events) == 0 {
+		err = fmt.Errorf("no moves were played against the engine")
+	}
+	return err
+}
+
+func TestRoomsUnderConcurrentLoad(t *testing.T) {
+	hub, url := startServer(t, 10*time.Millisecond)
+
+	const twoPlayerRooms, engineRooms = 24, 8
+	errs := make(chan error, twoPlayerRooms+engineRooms)
+	var wg sync.WaitGroup
+	for i := 0; i < twoPlayerRooms+engineRooms; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i < twoPlayerRooms {
+				errs <- playTwoPlayerRoom(url, int64(2*i))
+			} else {
+				errs <- playEngineRoom(url, int64(i))
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Each room closes once its players have disconnected and their
+	// grace periods have run out.
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.RoomCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := hub.RoomCount(); n != 0 {
+		t.Errorf("Expected every room to close, %d still open", n)
+	}
+}
+
+func TestJoinFullOrMissingRoom(t *testing.T) {
+	_, url := startServer(t, time.Minute)
+
+	var clients []*wsClient
+	for i := 0; i < 3; i++ {
+		c, err := dialClient(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.conn.Close()
+		clients = append(clients, c)
+	}
+
+	roomID, err := clients[0].createGame(map[string]interface{}{"boardSize": 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"game_joined", "error"} {
+		clients[i+1].send("join_game", map[string]interface{}{"roomId": roomID})
+		msg, _, err := clients[i+1].waitFor(expected)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected == "error" && msg.Data["message"] != "Game is full" {
+			t.Errorf("Expected the third player to find the game full, got %v", msg.Data["message"])
+		}
+	}
+
+	// The player turned away has no seat to play from.
+	clients[2].send("make_move", map[string]interface{}{"x": 0, "y": 0})
+	if msg, _, err := clients[2].waitFor("error"); err != nil || msg.Data["message"] != "Not in a game" {
+		t.Errorf("Expected Not in a game, got %v %v", msg.Data["message"], err)
+	}
+
+	clients[2].send("join_game", map[string]interface{}{"roomId": "NOROOM"})
+	if msg, _, err := clients[2].waitFor("error"); err != nil || msg.Data["message"] != "Room not found" {
+		t.Errorf("Expected Room not found, got %v %v", msg.Data["message"], err)
+	}
+}
+
+// startTwoPlayerGame connects two players to a new room, returning them
+// with the room's ID and White's session token.
+func startTwoPlayerGame(t *testing.T, url string) (*wsClient, *wsClient, string, string) {
+	t.Helper()
+	black, err := dialClient(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { black.conn.Close() })
+	roomID, err := black.createGame(map[string]interface{}{"boardSize": 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	white, err := dialClient(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	white.send("join_game", map[string]interface{}{"roomId": roomID})
+	joined, _, err := white.waitFor("game_joined")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := white.waitFor("game_started"); err != nil {
+		t.Fatal(err)
+	}
+	return black, white, roomID, joined.Data["token"].(string)
+}
+
+func TestReconnectRestoresSeat(t *testing.T) {
+	_, url := startServer(t, time.Minute)
+	black, white, roomID, token := startTwoPlayerGame(t, url)
+
+	black.send("make_move", map[string]interface{}{"x": 2, "y": 2})
+	if _, _, err := white.waitFor("move_made"); err != nil {
+		t.Fatal(err)
+	}
+	white.send("make_move", map[string]interface{}{"x": 6, "y": 6})
+	if _, _, err := white.waitFor("move_made"); err != nil {
+		t.Fatal(err)
+	}
+	lastSeq := white.seq
+
+	// White drops, and misses Black's next move.
+	white.conn.Close()
+	if msg, _, err := black.waitFor("player_left"); err != nil || msg.Data["color"] != "White" {
+		t.Fatalf("Expected White to leave, got %v %v", msg.Data, err)
+	}
+	black.send("make_move", map[string]interface{}{"x": 2, "y": 6})
+	if _, _, err := black.waitFor("move_made"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The seat stays White's while it is away.
+	other, err := dialClient(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.conn.Close()
+	other.send("join_game", map[string]interface{}{"roomId": roomID})
+	if msg, _, err := other.waitFor("error"); err != nil || msg.Data["message"] != "Game is full" {
+		t.Errorf("Expected the game to be full, got %v %v", msg.Data["message"], err)
+	}
+	other.send("reconnect", map[string]interface{}{"roomId": roomID, "token": "not-the-token"})
+	if msg, _, err := other.waitFor("error"); err != nil || msg.Data["message"] != "Invalid session" {
+		t.Errorf("Expected Invalid session, got %v %v", msg.Data["message"], err)
+	}
+
+	white, err = dialClient(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer white.conn.Close()
+	white.send("reconnect", map[string]interface{}{"roomId": roomID, "token": token, "lastSeq": lastSeq})
+	msg, _, err := white.waitFor("reconnected")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Data["color"] != "White" || msg.Data["info"].(map[string]interface{})["moveCount"] != float64(3) {
+		t.Errorf("Expected White's seat after 3 moves, got %v", msg.Data)
+	}
+	var missed []string
+	for _, event := range msg.Data["missed"].([]interface{}) {
+		missed = append(missed, event.(map[string]interface{})["type"].(string))
+	}
+	if strings.Join(missed, " ") != "player_left move_made" {
+		t.Errorf("Expected the missed player_left and move_made, got %v", missed)
+	}
+
+	// Play carries on from the new connection.
+	if _, _, err := black.waitFor("player_returned"); err != nil {
+		t.Fatal(err)
+	}
+	white.send("make_move", map[string]interface{}{"x": 6, "y": 2})
+	if msg, _, err := black.waitFor("move_made"); err != nil || msg.Data["color"] != "White" {
+		t.Errorf("Expected White's move after reconnecting, got %v %v", msg.Data, err)
+	}
+}
+
+func TestForfeitAfterGracePeriod(t *testing.T) {
+	hub, url := startServer(t, 20*time.Millisecond)
+	black, white, _, _ := startTwoPlayerGame(t, url)
+
+	white.conn.Close()
+	msg, _, err := black.waitFor("forfeit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Data["color"] != "White" || msg.Data["winner"] != "Black" {
+		t.Errorf("Expected White to forfeit to Black, got %v", msg.Data)
+	}
+
+	// With both players gone for good, the room closes.
+	black.conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.RoomCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if hub.RoomCount() != 0 {
+		t.Error("Expected the room to close")
+	}
+}
+
+func TestRoomAnswersWhileEngineThinks(t *testing.T) {
+	_, url := startServer(t, time.Minute)
+	c, err := dialClient(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.conn.Close()
+	if _, err := c.createGame(map[string]interface{}{"boardSize": 9, "engine": "mcts", "moveTime": 3000}); err != nil {
+		t.Fatal(err)
+	}
+
+	c.send("make_move", map[string]interface{}{"x": 4, "y": 4})
+	if _, _, err := c.waitFor("move_made"); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	c.send("get_valid_moves", nil)
+	_, events, err := c.waitFor("valid_moves")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) > 0 || time.Since(start) > time.Second {
+		t.Errorf("Expected an answer before the engine's move, got %v after %v", events, time.Since(start))
+	}
+
+	if msg, _, err := c.waitFor("move_made"); err != nil || msg.Data["color"] != "White" {
+		t.Errorf("Expected the engine's move, got %v %v", msg.Data, err)
+	}
+}
+
+func TestFailedJoinKeepsSeat(t *testing.T) {
+	_, url := startServer(t, time.Minute)
+	black, white, _, _ := startTwoPlayerGame(t, url)
+	defer white.conn.Close()
+	_, otherWhite, fullRoom, otherToken := startTwoPlayerGame(t, url)
+	defer otherWhite.conn.Close()
+
+	black.send("join_game", map[string]interface{}{"roomId": fullRoom})
+	if msg, _, err := black.waitFor("error"); err != nil || msg.Data["message"] != "Game is full" {
+		t.Fatalf("Expected the game to be full, got %v %v", msg.Data["message"], err)
+	}
+	black.send("reconnect", map[string]interface{}{"roomId": fullRoom, "token": otherToken + "x"})
+	if msg, _, err := black.waitFor("error"); err != nil || msg.Data["message"] != "Invalid session" {
+		t.Fatalf("Expected Invalid session, got %v %v", msg.Data["message"], err)
+	}
+
+	// Black is still seated in its own game.
+	black.send("make_move", map[string]interface{}{"x": 4, "y": 4})
+	msg, _, err := white.waitFor("move_made")
+	if err != nil || msg.Data["color"] != "Black" {
+		t.Fatalf("Expected Black's move, got %v %v", msg.Data, err)
+	}
+	white.send("get_valid_moves", nil)
+	if _, _, err := white.waitFor("valid_moves"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReconnectAfterTooManyMessages(t *testing.T) {
+	_, url := startServer(t, time.Minute)
+	black, white, roomID, token := startTwoPlayerGame(t, url)
+	lastSeq := white.seq
+
+	white.conn.Close()
+	if _, _, err := black.waitFor("player_left"); err != nil {
+		t.Fatal(err)
+	}
+	black.send("make_move", map[string]interface{}{"x": 4, "y": 4})
+	for i := 0; i < 150; i++ {
+		for _, command := range []struct{ send, reply string }{{"undo", "undo"}, {"redo", "move_made"}} {
+			black.send(command.send, nil)
+			if _, _, err := black.waitFor(command.reply); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	white, err := dialClient(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer white.conn.Close()
+	white.send("reconnect", map[string]interface{}{"roomId": roomID, "token": token, "lastSeq": lastSeq})
+	msg, _, err := white.waitFor("reconnected")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Data["resync"] != true || len(msg.Data["missed"].([]interface{})) != 0 {
+		t.Errorf("Expected only the current state, got resync %v with %d missed", msg.Data["resync"], len(msg.Data["missed"].([]interface{})))
+	}
+	if msg.Data["info"].(map[string]interface{})["moveCount"] != float64(1) {
+		t.Errorf("Expected the state after Black's move, got %v", msg.Data["info"])
+	}
+}
+
+func TestReconnectReplacesConnection(t *testing.T) {
+	_, url := startServer(t, time.Minute)
+	black, white, roomID, token := startTwoPlayerGame(t, url)
+	defer white.conn.Close()
+
+	// Black cannot take White's seat as well.
+	black.send("reconnect", map[string]interface{}{"roomId": roomID, "token": token})
+	if msg, _, err := black.waitFor("error"); err != nil || msg.Data["message"] != "Already playing Black" {
+		t.Fatalf("Expected Already playing Black, got %v %v", msg.Data["message"], err)
+	}
+
+	second, err := dialClient(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.conn.Close()
+	second.send("reconnect", map[string]interface{}{"roomId": roomID, "token": token})
+	if _, _, err := second.waitFor("reconnected"); err != nil {
+		t.Fatal(err)
+	}
+	if msg, _, err := white.waitFor("replaced"); err != nil || msg.Data["color"] != "White" {
+		t.Fatalf("Expected the first connection to be replaced, got %v %v", msg.Data, err)
+	}
+
+	black.send("make_move", map[string]interface{}{"x": 4, "y": 4})
+	if _, _, err := second.waitFor("move_made"); err != nil {
+		t.Fatal(err)
+	}
+	white.send("make_move", map[string]interface{}{"x": 2, "y": 2})
+	if msg, _, err := white.waitFor("error"); err != nil || msg.Data["message"] != "Not in a game" {
+		t.Errorf("Expected the replaced connection to have no seat, got %v %v", msg.Data["message"], err)
+	}
+}
+
+func TestEngineMovesAgainAfterUndo(t *testing.T) {
+	_, url := startServer(t, time.Minute)
+	c, err := dialClient(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.conn.Close()
+	if _, err := c.createGame(map[string]interface{}{"boardSize": 9, "engine": "random", "seed": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	c.send("make_move", map[string]interface{}{"x": 4, "y": 4})
+	for _, color := range []string{"Black", "White"} {
+		if msg, _, err := c.waitFor("move_made"); err != nil || msg.Data["color"] != color {
+			t.Fatalf("Expected %s's move, got %v %v", color, msg.Data, err)
+		}
+	}
+
+	// Taking back the engine's reply puts it on move again.
+	c.send("undo", nil)
+	if _, _, err := c.waitFor("undo"); err != nil {
+		t.Fatal(err)
+	}
+	if msg, _, err := c.waitFor("move_made"); err != nil || msg.Data["color"] != "White" {
+		t.Errorf("Expected the engine to move again, got %v %v", msg.Data, err)
+	}
+}
+
+func TestRedoPassStartsScoring(t *testing.T) {
+	_, url := startServer(t, time.Minute)
+	black, white, _, _ := startTwoPlayerGame(t, url)
+	defer white.conn.Close()
+
+	black.send("pass", nil)
+	if _, _, err := white.waitFor("pass"); err != nil {
+		t.Fatal(err)
+	}
+	white.send("pass", nil)
+	if _, _, err := black.waitFor("scoring_started"); err != nil {
+		t.Fatal(err)
+	}
+
+	black.send("undo", nil)
+	if _, _, err := black.waitFor("undo"); err != nil {
+		t.Fatal(err)
+	}
+	black.send("redo", nil)
+	if msg, _, err := white.waitFor("pass"); err != nil || msg.Data["color"] != "White" {
+		t.Fatalf("Expected White's pass to be redone, got %v %v", msg.Data, err)
+	}
+	if _, _, err := white.waitFor("scoring_started"); err != nil {
+		t.Errorf("Expected the redone pass to start scoring: %v", err)
+	}
+}
+
+func TestGTPEngineResignsInRoom(t *testing.T) {
+	gtp.RegisterEngine("test-resigner", fakeEngine(t), "-moves", "resign")
+	_, url := startServer(t, time.Minute)
+	c, err := dialClient(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.conn.Close()
+	if _, err := c.createGame(map[string]interface{}{"boardSize": 9, "engine": "test-resigner"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c.send("make_move", map[string]interface{}{"x": 4, "y": 4})
+	if msg, _, err := c.waitFor("resign"); err != nil || msg.Data["color"] != "White" {
+		t.Errorf("Expected the engine to resign, got %v %v", msg.Data, err)
+	}
+}
@@ -2,7 +2,10 @@ class GoBoard {
     constructor(canvasId, size = 19) {
         this.canvas = document.getElementById(canvasId);
         this.ctx = this.canvas.getContext('2d');
+        // size is the width; boards may be rectangular.
         this.size = size;
+        this.width = size;
+        this.height = size;
         this.cellSize = 30;
         this.padding = 20;
         this.board = Array(size).fill().map(() => Array(size).fill(0));
@@ -10,6 +13,7 @@ class GoBoard {
         this.validMoves = [];
         this.showValidMoves = false;
         this.territoryMarkers = [];
+        this.deadStones = [];
         
         this.setupCanvas();
         this.setupEventListeners();
@@ -17,9 +21,8 @@ class GoBoard {
     }
 
     setupCanvas() {
-        const totalSize = (this.size - 1) * this.cellSize + 2 * this.padding;
-        this.canvas.width = totalSize;
-        this.canvas.height = totalSize;
+        this.canvas.width = (this.width - 1) * this.cellSize + 2 * this.padding;
+        this.canvas.height = (this.height - 1) * this.cellSize + 2 * this.padding;
     }
 
     setupEventListeners() {
@@ -36,7 +39,7 @@ class GoBoard {
         const gridX = Math.round((x - this.padding) / this.cellSize);
         const gridY = Math.round((y - this.padding) / this.cellSize);
         
-        if (gridX >= 0 && gridX < this.size && gridY >= 0 && gridY < this.size) {
+        if (gridX >= 0 && gridX < this.width && gridY >= 0 && gridY < this.height) {
             this.hoverPos = { x: gridX, y: gridY };
             this.draw();
         }
@@ -55,7 +58,7 @@ class GoBoard {
         const gridX = Math.round((x - this.padding) / this.cellSize);
         const gridY = Math.round((y - this.padding) / this.cellSize);
         
-        if (gridX >= 0 && gridX < this.size && gridY >= 0 && gridY < this.size) {
+        if (gridX >= 0 && gridX < this.width && gridY >= 0 && gridY < this.height) {
             if (this.board[gridX][gridY] === 0) {
                 if (this.onMove) {
                     this.onMove(gridX, gridY);
@@ -78,6 +81,10 @@ class GoBoard {
         
         this.drawStones();
         
+        if (this.deadStones.length > 0) {
+            this.drawDeadStones();
+        }
+        
         if (this.territoryMarkers.length > 0) {
             this.drawTerritory();
         }
@@ -95,19 +102,24 @@ class GoBoard {
         this.ctx.strokeStyle = '#000';
         this.ctx.lineWidth = 1;
         
-        for (let i = 0; i < this.size; i++) {
+        const left = this.padding;
+        const right = this.padding + (this.width - 1) * this.cellSize;
+        const top = this.padding;
+        const bottom = this.padding + (this.height - 1) * this.cellSize;
+
+        for (let i = 0; i < this.width; i++) {
             const x = this.padding + i * this.cellSize;
-            const y1 = this.padding;
-            const y2 = this.padding + (this.size - 1) * this.cellSize;
-            
             this.ctx.beginPath();
-            this.ctx.moveTo(x, y1);
-            this.ctx.lineTo(x, y2);
+            this.ctx.moveTo(x, top);
+            this.ctx.lineTo(x, bottom);
             this.ctx.stroke();
-            
+        }
+
+        for (let i = 0; i < this.height; i++) {
+            const y = this.padding + i * this.cellSize;
             this.ctx.beginPath();
-            this.ctx.moveTo(y1, x);
-            this.ctx.lineTo(y2, x);
+            this.ctx.moveTo(left, y);
+            this.ctx.lineTo(right, y);
             this.ctx.stroke();
         }
     }
@@ -127,6 +139,9 @@ class GoBoard {
     }
 
     getStarPoints() {
+        if (this.width !== this.height) {
+            return [];
+        }
         if (this.size === 9) {
             return [
                 {x: 2, y: 2}, {x: 6, y: 2},
@@ -155,22 +170,24 @@ class GoBoard {
         this.ctx.textAlign = 'center';
         this.ctx.textBaseline = 'middle';
         
-        for (let i = 0; i < this.size; i++) {
-            const letter = String.fromCharCode(65 + i);
+        for (let i = 0; i < this.width; i++) {
+            const letter = GoBoard.columnLabel(i);
             const x = this.padding + i * this.cellSize;
             
             this.ctx.fillText(letter, x, 8);
             this.ctx.fillText(letter, x, this.canvas.height - 8);
-            
-            const number = this.size - i;
+        }
+
+        for (let i = 0; i < this.height; i++) {
+            const number = this.height - i;
             this.ctx.fillText(number, 8, this.padding + i * this.cellSize);
             this.ctx.fillText(number, this.canvas.width - 8, this.padding + i * this.cellSize);
         }
     }
 
     drawStones() {
-        for (let x = 0; x < this.size; x++) {
-            for (let y = 0; y < this.size; y++) {
+        for (let x = 0; x < this.width; x++) {
+            for (let y = 0; y < this.height; y++) {
                 if (this.board[x][y] !== 0) {
                     this.drawStone(x, y, this.board[x][y]);
                 }
@@ -273,6 +290,24 @@ class GoBoard {
         });
     }
 
+    drawDeadStones() {
+        this.ctx.strokeStyle = '#ff0000';
+        this.ctx.lineWidth = 2;
+        const r = this.cellSize * 0.25;
+        
+        this.deadStones.forEach(stone => {
+            const x = this.padding + stone.x * this.cellSize;
+            const y = this.padding + stone.y * this.cellSize;
+            
+            this.ctx.beginPath();
+            this.ctx.moveTo(x - r, y - r);
+            this.ctx.lineTo(x + r, y + r);
+            this.ctx.moveTo(x + r, y - r);
+            this.ctx.lineTo(x - r, y + r);
+            this.ctx.stroke();
+        });
+    }
+
     updateBoard(boardState) {
         this.board = boardState;
         this.draw();
@@ -298,12 +333,30 @@ class GoBoard {
         this.draw();
     }
 
-    reset(size) {
-        this.size = size;
-        this.board = Array(size).fill().map(() => Array(size).fill(0));
+    setDeadStones(stones) {
+        this.deadStones = stones;
+        this.draw();
+    }
+
+    // Column labels skip I, as in Go notation; past Z they take two
+    // letters.
+    static columnLabel(x) {
+        const letters = 'ABCDEFGHJKLMNOPQRSTUVWXYZ';
+        if (x < letters.length) {
+            return letters[x];
+        }
+        return letters[Math.floor(x / letters.length) - 1] + letters[x % letters.length];
+    }
+
+    reset(width, height = width) {
+        this.size = width;
+        this.width = width;
+        this.height = height;
+        this.board = Array(width).fill().map(() => Array(height).fill(0));
         this.lastMove = null;
         this.validMoves = [];
         this.territoryMarkers = [];
+        this.deadStones = [];
         this.setupCanvas();
         this.draw();
     }
@@ -11,6 +11,7 @@ class GoGame {
         this.roomId = null;
         this.gameTimer = null;
         this.startTime = null;
+        this.scoring = false;
         
         this.setupEventListeners();
         this.initializeGame();
@@ -32,7 +33,10 @@ class GoGame {
         document.getElementById('pass-btn').addEventListener('click', () => this.pass());
         document.getElementById('resign-btn').addEventListener('click', () => this.resign());
         document.getElementById('undo-btn').addEventListener('click', () => this.undo());
+        document.getElementById('redo-btn').addEventListener('click', () => this.redo());
         document.getElementById('new-game-btn').addEventListener('click', () => this.newGame());
+        document.getElementById('accept-score-btn').addEventListener('click', () => this.wsConnection.sendAcceptScore());
+        document.getElementById('resume-play-btn').addEventListener('click', () => this.wsConnection.sendResumePlay());
         
         document.getElementById('create-room-btn').addEventListener('click', () => this.createRoom());
         document.getElementById('join-room-btn').addEventListener('click', () => this.joinRoom());
@@ -151,6 +155,11 @@ class GoGame {
                 this.makeAIMove();
             }, 500);
         } else if (this.gameMode === 'multiplayer' && this.wsConnection) {
+            // After both players pass, clicking a stone toggles its group dead or alive
+            if (this.scoring) {
+                this.wsConnection.sendToggleDead(x, y);
+                return;
+            }
             if (this.currentTurn !== this.playerColor) {
                 return;
             }
@@ -277,6 +286,12 @@ class GoGame {
         }
     }
 
+    redo() {
+        if (this.wsConnection) {
+            this.wsConnection.sendRedo();
+        }
+    }
+
     newGame() {
         this.gameStarted = false;
         this.moveHistory = [];
@@ -373,8 +388,8 @@ class GoGame {
     }
 
     getPositionNotation(x, y) {
-        const letter = String.fromCharCode(65 + x);
-        const number = this.board.size - y;
+        const letter = GoBoard.columnLabel(x);
+        const number = this.board.height - y;
         return `${letter}${number}`;
     }
 
@@ -425,6 +440,16 @@ class GoGame {
         document.getElementById('modal').style.display = 'flex';
     }
 
+    setScoring(active) {
+        this.scoring = active;
+        const display = active ? 'inline-block' : 'none';
+        document.getElementById('accept-score-btn').style.display = display;
+        document.getElementById('resume-play-btn').style.display = display;
+        if (!active) {
+            this.board.setDeadStones([]);
+        }
+    }
+
     closeModal() {
         document.getElementById('modal').style.display = 'none';
     }
@@ -5,6 +5,11 @@ class WebSocketConnection {
         this.reconnectAttempts = 0;
         this.maxReconnectAttempts = 5;
         this.reconnectDelay = 1000;
+
+        // The seat to take back after a dropped connection or a reload,
+        // and the last room message seen.
+        this.session = JSON.parse(sessionStorage.getItem('gosim-session') || 'null');
+        this.lastSeq = 0;
         
         this.connect();
     }
@@ -30,6 +35,29 @@ class WebSocketConnection {
         console.log('WebSocket connected');
         this.reconnectAttempts = 0;
         this.game.updateStatus('Connected to server');
+
+        if (this.session) {
+            this.reconnecting = true;
+            this.send({
+                type: 'reconnect',
+                data: {
+                    roomId: this.session.roomId,
+                    token: this.session.token,
+                    lastSeq: this.lastSeq
+                }
+            });
+        }
+    }
+
+    saveSession(roomId, token) {
+        this.session = { roomId: roomId, token: token };
+        this.lastSeq = 0;
+        sessionStorage.setItem('gosim-session', JSON.stringify(this.session));
+    }
+
+    clearSession() {
+        this.session = null;
+        sessionStorage.removeItem('gosim-session');
     }
 
     onMessage(event) {
@@ -70,6 +98,10 @@ class WebSocketConnection {
 
     handleMessage(data) {
         console.log('Received message:', data);
+
+        if (data.seq) {
+            this.lastSeq = data.seq;
+        }
         
         switch (data.type) {
             case 'game_created':
@@ -93,12 +125,31 @@ class WebSocketConnection {
             case 'game_over':
                 this.handleGameOver(data);
                 break;
+            case 'scoring_started':
+            case 'scoring_updated':
+                this.handleScoring(data);
+                break;
+            case 'play_resumed':
+                this.handlePlayResumed(data);
+                break;
             case 'undo':
                 this.handleUndo(data);
                 break;
             case 'valid_moves':
                 this.handleValidMoves(data);
                 break;
+            case 'reconnected':
+                this.handleReconnected(data);
+                break;
+            case 'player_left':
+                this.game.updateStatus(`${data.data.color} disconnected. Waiting ${Math.round(data.data.gracePeriod / 1000)}s for them to return...`);
+                break;
+            case 'player_returned':
+                this.game.updateStatus(`${data.data.color} reconnected.`);
+                break;
+            case 'forfeit':
+                this.handleForfeit(data);
+                break;
             case 'error':
                 this.handleError(data);
                 break;
@@ -106,6 +157,7 @@ class WebSocketConnection {
     }
 
     handleGameCreated(data) {
+        this.saveSession(data.data.roomId, data.data.token);
         this.game.roomId = data.data.roomId;
         this.game.playerColor = 'black';
         document.getElementById('room-info').innerHTML = `
@@ -117,8 +169,10 @@ class WebSocketConnection {
     }
 
     handleGameJoined(data) {
+        this.saveSession(data.data.roomId, data.data.token);
         this.game.roomId = data.data.roomId;
         this.game.playerColor = 'white';
+        this.game.board.reset(data.data.boardWidth, data.data.boardHeight);
         document.getElementById('room-info').innerHTML = `
             <strong>Room ID: ${this.game.roomId}</strong><br>
             You are playing: White<br>
@@ -156,7 +210,7 @@ class WebSocketConnection {
         this.game.moveHistory.push({
             move: this.game.moveHistory.length + 1,
             color: moveColor,
-            position: this.game.getPositionNotation(data.data.x, data.data.y)
+            position: data.data.vertex || this.game.getPositionNotation(data.data.x, data.data.y)
         });
         
         this.game.updateMoveHistory();
@@ -186,8 +240,26 @@ class WebSocketConnection {
         this.game.showModal('Game Over', `${data.data.color} resigned. ${data.data.winner} wins!`);
     }
 
+    handleScoring(data) {
+        this.game.setScoring(true);
+        this.game.board.setDeadStones(data.data.deadStones);
+        
+        const scores = data.data.scores;
+        this.game.updateStatus(`Mark dead stones, then accept. Black: ${scores.Black}, White: ${scores.White} (${scores.result})`);
+    }
+
+    handlePlayResumed(data) {
+        this.game.setScoring(false);
+        this.game.board.updateBoard(data.data.board);
+        
+        this.game.currentTurn = data.data.info.currentTurn.toLowerCase();
+        this.game.updateTurnIndicator();
+        this.game.updateStatus(`${data.data.color} resumed play.`);
+    }
+
     handleGameOver(data) {
         this.game.gameStarted = false;
+        this.game.setScoring(false);
         
         let message = '';
         if (data.data.winner) {
@@ -221,6 +293,36 @@ class WebSocketConnection {
         this.game.updateTurnIndicator();
     }
 
+    handleReconnected(data) {
+        this.reconnecting = false;
+        const state = data.data;
+        this.game.roomId = state.roomId;
+        this.game.playerColor = state.color.toLowerCase();
+        document.getElementById('player-color').textContent = `Playing as ${state.color}`;
+        document.getElementById('menu-screen').style.display = 'none';
+        document.getElementById('game-controls').style.display = 'block';
+
+        // After a reload nothing has been seen, so the missed messages
+        // rebuild the whole game; the snapshot then settles the position.
+        if (this.lastSeq === 0) {
+            this.game.board.reset(state.boardWidth, state.boardHeight);
+            this.game.moveHistory = [];
+        }
+        state.missed.forEach(message => this.handleMessage(message));
+
+        this.lastSeq = state.seq;
+        this.game.board.updateBoard(state.board);
+        this.game.gameStarted = !state.info.isOver;
+        this.game.currentTurn = state.info.currentTurn.toLowerCase();
+        this.game.updateTurnIndicator();
+        this.game.updateStatus('Reconnected to the game');
+    }
+
+    handleForfeit(data) {
+        this.game.gameStarted = false;
+        this.game.showModal('Game Over', `${data.data.color} did not return in time. ${data.data.winner} wins!`);
+    }
+
     handleValidMoves(data) {
         const moves = data.data.moves;
         this.game.board.setValidMoves(moves);
@@ -228,6 +330,11 @@ class WebSocketConnection {
 
     handleError(data) {
         console.error('Server error:', data.data.message);
+        if (this.reconnecting) {
+            // The seat is gone, so start afresh.
+            this.reconnecting = false;
+            this.clearSession();
+        }
         this.game.updateStatus(`Error: ${data.data.message}`);
     }
 
@@ -288,6 +395,37 @@ class WebSocketConnection {
         });
     }
 
+    sendRedo() {
+        this.send({
+            type: 'redo',
+            data: {}
+        });
+    }
+
+    sendToggleDead(x, y) {
+        this.send({
+            type: 'toggle_dead',
+            data: {
+                x: x,
+                y: y
+            }
+        });
+    }
+
+    sendAcceptScore() {
+        this.send({
+            type: 'accept_score',
+            data: {}
+        });
+    }
+
+    sendResumePlay() {
+        this.send({
+            type: 'resume_play',
+            data: {}
+        });
+    }
+
     getValidMoves() {
         this.send({
             type: 'get_valid_moves',